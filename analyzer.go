@@ -0,0 +1,124 @@
+package minsearch
+
+import (
+	"errors"
+
+	"github.com/boltdb/bolt"
+)
+
+const dbStatsAnalyzer = `analyzer`
+
+// Analyzer reduces the normalized segments of the indexed texts and the queries
+// to the segments that are stored in the index.
+type Analyzer uint8
+
+const (
+	// DefaultAnalyzer stores the normalized segments unchanged.
+	DefaultAnalyzer Analyzer = iota
+	// GermanAnalyzer stores the stems of the normalized segments
+	// found by a light German stemmer, so "Häuser" matches "Haus".
+	GermanAnalyzer
+	// EnglishAnalyzer stores the stems of the normalized segments
+	// found by a light English stemmer, so "running" matches "run".
+	EnglishAnalyzer
+)
+
+// ErrAnalyzerMismatch is returned if a File is opened with another Analyzer
+// than it was created with.
+var ErrAnalyzerMismatch = errors.New("minsearch: the File was created with another analyzer")
+
+// ErrUnknownAnalyzer is returned if a File uses an Analyzer this version doesn't know.
+var ErrUnknownAnalyzer = errors.New("minsearch: unknown analyzer")
+
+// stem returns the segment stored in the index for the normalized segment.
+func (a Analyzer) stem(segment []byte) []byte {
+	switch a {
+	case GermanAnalyzer:
+		return germanStem(segment)
+	case EnglishAnalyzer:
+		return englishStem(segment)
+	}
+	return segment
+}
+
+// fold returns the normalized segment with only the changes of the Analyzer
+// that don't depend on the end of the segment, so a prefix of the segment
+// still matches the stems starting with it.
+func (a Analyzer) fold(segment []byte) []byte {
+	if a == GermanAnalyzer {
+		return []byte(string(foldUmlauts([]rune(string(segment)))))
+	}
+	return segment
+}
+
+// stemTerms returns the terms with their segments replaced by their stems.
+// The prefixes of prefix terms and the literal parts of wildcard patterns
+// are only folded (see fold), so they still match the stems containing them.
+func (a Analyzer) stemTerms(terms []node) []node {
+	if a == DefaultAnalyzer {
+		return terms
+	}
+	stemmed := make([]node, len(terms))
+	for idx, term := range terms {
+		stemmed[idx] = a.stemNode(term)
+	}
+	return stemmed
+}
+
+func (a Analyzer) stemNode(n node) node {
+	switch t := n.(type) {
+	case termNode:
+		return a.stemTerm(t)
+	case nearNode:
+		terms := make([]termNode, len(t.terms))
+		for idx, term := range t.terms {
+			terms[idx] = a.stemTerm(term)
+		}
+		return nearNode{terms: terms, distances: t.distances}
+	case opNode:
+		return opNode{op: t.op, children: a.stemTerms(t.children)}
+	case patternNode:
+		return a.foldPattern(t)
+	case notNode:
+		return notNode{child: a.stemNode(t.child)}
+	}
+	return n
+}
+
+func (a Analyzer) stemTerm(n termNode) termNode {
+	segments := make([][]byte, len(n.segments))
+	for idx, segment := range n.segments {
+		if n.prefix && idx == len(n.segments)-1 {
+			segments[idx] = a.fold(segment)
+		} else {
+			segments[idx] = a.stem(segment)
+		}
+	}
+	n.segments = segments
+	return n
+}
+
+// openAnalyzer returns the Analyzer of the File.
+// The Analyzer of a new File is stored; an existing File keeps its Analyzer,
+// so the given Analyzer must be the same or DefaultAnalyzer.
+func openAnalyzer(tx *bolt.Tx, analyzer Analyzer, isEmpty bool) (Analyzer, error) {
+	stats := tx.Bucket([]byte{bucketStats})
+	if stored := stats.Get([]byte(dbStatsAnalyzer)); len(stored) == 1 {
+		switch {
+		case Analyzer(stored[0]) > EnglishAnalyzer:
+			return 0, ErrUnknownAnalyzer
+		case analyzer != DefaultAnalyzer && analyzer != Analyzer(stored[0]):
+			return 0, ErrAnalyzerMismatch
+		}
+		return Analyzer(stored[0]), nil
+	}
+	switch {
+	case analyzer == DefaultAnalyzer:
+		return analyzer, nil
+	case analyzer > EnglishAnalyzer:
+		return 0, ErrUnknownAnalyzer
+	case !isEmpty:
+		return 0, ErrAnalyzerMismatch
+	}
+	return analyzer, stats.Put([]byte(dbStatsAnalyzer), []byte{byte(analyzer)})
+}
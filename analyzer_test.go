@@ -0,0 +1,111 @@
+package minsearch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnalyzer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minsearch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "test.idx")
+
+	f, err := OpenWithOptions(filename, Options{Analyzer: GermanAnalyzer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs := []Pair{
+		{ID: 1, Text: []byte("Die Häuser der Stadt")},
+		{ID: 2, Text: []byte("Ein Haus am See")},
+		{ID: 3, Text: []byte("Müller aus Schöneberg")},
+		{ID: 4, Text: []byte("Bücher")},
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if _, err := OpenWithOptions(filename, Options{Analyzer: EnglishAnalyzer}); err != ErrAnalyzerMismatch {
+		t.Errorf("OpenWithOptions with another Analyzer returned %v; expected ErrAnalyzerMismatch", err)
+	}
+	// the stored Analyzer is used
+	f, err = Open(filename, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for query, expected := range map[string][]ID{
+		"haus":         {1, 2},
+		"Häusern":      {1, 2},
+		`"die häuser"`: {1},
+		"hau*":         {1, 2},
+		"städte":       {1},
+		"mül*":         {3},
+		"schö*":        {3},
+		"büch*":        {4},
+		"*öneberg":     {3},
+	} {
+		results, err := f.SearchWithOptions([]byte(query), SearchOptions{Syntax: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := sortedIDs(results); !equalIDs(ids, expected) {
+			t.Errorf("Search(%s) = %v; expected IDs %v", query, results, expected)
+		}
+	}
+	// the prefix is folded like the indexed segments
+	for _, query := range []string{"schö", "sch"} {
+		completions, err := f.Complete([]byte(query), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(completions) != 1 || completions[0] != "schoneberg" {
+			t.Errorf("Complete(%s) = %q; expected [schoneberg]", query, completions)
+		}
+	}
+
+	en, cleanup := openTestFile(t, Options{Analyzer: EnglishAnalyzer})
+	defer cleanup()
+	if err := en.IndexPair(Pair{ID: 1, Text: []byte("ponies running happily")}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// suggestions and completions are reduced segments, which find the results again
+	suggestions, err := en.Suggest([]byte("ponie runing"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0] != "poni run" {
+		t.Errorf("Suggest(ponie runing) = %q; expected [poni run]", suggestions)
+	}
+	completions, err := en.Complete([]byte("running pon"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(completions) != 1 || completions[0] != "running poni" {
+		t.Errorf("Complete(running pon) = %q; expected [running poni]", completions)
+	}
+	for _, query := range append(suggestions, completions...) {
+		results, err := en.Search([]byte(query), Intersection, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := resultIDs(results); !equalIDs(ids, []ID{1}) {
+			t.Errorf("Search(%s) = %v; expected [1]", query, ids)
+		}
+	}
+
+	// segments with results are kept unchanged
+	suggestions, err = en.Suggest([]byte("ponies runing"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0] != "ponies run" {
+		t.Errorf("Suggest(ponies runing) = %q; expected [ponies run]", suggestions)
+	}
+}
@@ -0,0 +1,61 @@
+package minsearch
+
+import "math"
+
+// BM25 is a Scorer that ranks the results using Okapi BM25.
+// The index score is the number of occurrences of a segment in a text (term frequency),
+// so a File searched with BM25 must also be indexed with BM25, which is stored
+// in the File (see Options.Scorer), and maxIDs keeps the results with the most
+// occurrences of a segment.
+// If multiple texts are indexed for an ID in the same field, the highest term frequency is used.
+// The inverse document frequency of a segment is calculated from the
+// length of its result list, so it's too small if maxIDs limited the list while indexing.
+// Document lengths and counts are those of the field of the segment (see Pair.Field),
+// so a title is normalized by the length of the title and not by the length of the full text.
+// If the File has no document statistics, the inverse document frequency compares the
+// length of the result list with the average length calculated by UpdateStatistics.
+type BM25 struct {
+	// K1 controls the saturation of the term frequency; 1.2 is a common value.
+	K1 float32
+	// B controls the normalization by document length; 0.75 is a common value.
+	B float32
+}
+
+// SearchBM25 works like Search but ranks the results using BM25{K1: 1.2, B: 0.75}.
+// The File must be indexed with BM25 (see BM25); otherwise ErrScorerMismatch is returned.
+func (f *File) SearchBM25(query []byte, setOp SetOperation, maxResults int) ([]Result, error) {
+	return f.SearchWithOptions(query, SearchOptions{SetOperation: setOp, MaxResults: maxResults, Scorer: BM25{K1: 1.2, B: 0.75}})
+}
+
+// IndexScore implements Scorer.
+func (BM25) IndexScore(count, length int) Score {
+	return Score(count)
+}
+
+// QueryScore implements Scorer.
+func (b BM25) QueryScore(r Result, stats QueryStats) Score {
+	docFreq := float64(stats.DocFreq)
+	var idf Score
+	if stats.DocCount > 0 {
+		docCount := math.Max(float64(stats.DocCount), docFreq)
+		idf = Score(math.Log(1 + (docCount-docFreq+0.5)/(docFreq+0.5)))
+	} else {
+		// no documents counted: segments with fewer results than the average are rarer
+		avgDocFreq := math.Max(float64(stats.AvgDocFreq), 1)
+		idf = Score(math.Log(1 + avgDocFreq/docFreq))
+	}
+	avgLength := stats.AvgLength
+	length := Score(stats.DocLength(r.ID))
+	if length == 0 {
+		length = avgLength // length unknown
+	}
+	if length == 0 {
+		length = 1 // no statistics
+	}
+	relLength := Score(1)
+	if avgLength > 0 {
+		relLength = length / avgLength
+	}
+	tf := r.Score
+	return idf * tf * (b.K1 + 1) / (tf + b.K1*(1-b.B+b.B*relLength))
+}
@@ -0,0 +1,187 @@
+package minsearch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestSearchBM25(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{Scorer: BM25{K1: 1.2, B: 0.75}})
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("the the the")},
+		{ID: 2, Text: []byte("the cat")},
+		{ID: 3, Text: []byte("the dog")},
+		{ID: 4, Text: []byte("the zebra and more words")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := f.SearchBM25([]byte("the zebra"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 || results[0].ID != 4 {
+		t.Errorf("SearchBM25(the zebra) = %v; expected ID 4 first of 4 results", results)
+	}
+
+	results, err = f.SearchBM25([]byte("the dog"), Intersection, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := resultIDs(results); !equalIDs(ids, []ID{3}) {
+		t.Errorf("SearchBM25(the dog) = %v; expected [3]", ids)
+	}
+
+	// only relevant segments count for the length of a text
+	if err := f.IndexBatch([]Pair{
+		{ID: 5, Text: []byte("berlin, mauer; zoo!")},
+		{ID: 6, Text: []byte("berlin mauer zoo")},
+	}, 0); err != nil {
+		t.Fatal(err)
+	}
+	results, err = f.SearchBM25([]byte("berlin"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Score != results[1].Score {
+		t.Errorf("SearchBM25(berlin) = %v; expected 2 results with the same score", results)
+	}
+}
+
+func TestBM25Stored(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minsearch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pairs := []Pair{
+		{ID: 1, Text: []byte("berlin mauer")},
+		{ID: 2, Text: []byte("berlin berlin")},
+	}
+
+	bm25File := filepath.Join(dir, "bm25.idx")
+	f, err := OpenWithOptions(bm25File, Options{Scorer: BM25{K1: 1.2, B: 0.75}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.SearchWithOptions([]byte("berlin"), SearchOptions{Scorer: DefaultScorer{}}); err != ErrScorerMismatch {
+		t.Errorf("SearchWithOptions with DefaultScorer returned %v; expected ErrScorerMismatch", err)
+	}
+	f.Close()
+
+	if _, err := OpenWithOptions(bm25File, Options{Scorer: DefaultScorer{}}); err != ErrScorerMismatch {
+		t.Errorf("OpenWithOptions with DefaultScorer returned %v; expected ErrScorerMismatch", err)
+	}
+	// BM25 is used for a File indexed with BM25
+	f, err = Open(bm25File, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.IndexPair(Pair{ID: 3, Text: []byte("berlin berlin berlin")}, 0); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := f.SearchBM25([]byte("berlin"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := f.Search([]byte("berlin"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(results) != fmt.Sprint(expected) {
+		t.Errorf("Search(berlin) = %v; expected the results of SearchBM25 %v", results, expected)
+	}
+	f.Close()
+
+	defaultFile := filepath.Join(dir, "default.idx")
+	f, err = Open(defaultFile, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.SearchBM25([]byte("berlin"), Union, 0); err != ErrScorerMismatch {
+		t.Errorf("SearchBM25 of a File without BM25 returned %v; expected ErrScorerMismatch", err)
+	}
+	f.Close()
+	if _, err := OpenWithOptions(defaultFile, Options{Scorer: BM25{K1: 1.2, B: 0.75}}); err != ErrScorerMismatch {
+		t.Errorf("OpenWithOptions with BM25 returned %v; expected ErrScorerMismatch", err)
+	}
+}
+
+func TestSearchBM25Fields(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{Scorer: BM25{K1: 1.2, B: 0.75}})
+	defer cleanup()
+
+	var body []byte
+	for i := 0; i < 1000; i++ {
+		body = append(body, " wort"...)
+	}
+	pairs := []Pair{
+		{ID: 1, Text: []byte("Berlin"), Field: "title"},
+		{ID: 1, Text: append([]byte("berlin"), body...)},
+		{ID: 2, Text: []byte("Mauer"), Field: "title"},
+		{ID: 2, Text: []byte("berlin berlin berlin mauer")},
+		{ID: 3, Text: []byte("Bern"), Field: "title"},
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// the term frequency is stored, not derived from the longest text of the ID
+	results, err := f.SearchWithOptions([]byte("title:berlin"), SearchOptions{Syntax: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != 1 {
+		t.Fatalf("Search(title:berlin) = %v; expected ID 1", results)
+	}
+	e, err := f.ExplainWithOptions([]byte("title:berlin"), 1, SearchOptions{Syntax: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tf := e.Children[0].PostingScore; tf != 1 {
+		t.Errorf("term frequency of title:berlin = %v; expected 1", tf)
+	}
+	title := results[0].Score
+
+	// the body of ID 2 is short and contains berlin three times
+	results, err = f.SearchWithOptions([]byte("berlin"), SearchOptions{FieldWeights: map[string]float32{"title": 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != 2 {
+		t.Errorf("Search(berlin) in the full text = %v; expected ID 2 first", results)
+	}
+	// the title is normalized by the title lengths only, so its match outweighs the long text
+	if len(results) == 2 && results[1].Score >= title {
+		t.Errorf("score of berlin in the long text of ID 1 = %v; expected less than the title score %v", results[1].Score, title)
+	}
+
+	if err := f.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	err = f.db.View(func(tx *bolt.Tx) error {
+		for field, expected := range map[string]uint32{"": 1, "title": 2} {
+			if c := readCollection(tx, field); c.docCount != expected {
+				t.Errorf("document count of field %q = %d; expected %d", field, c.docCount, expected)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
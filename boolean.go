@@ -0,0 +1,623 @@
+package minsearch
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tim-st/go-uniseg"
+)
+
+// SearchQuery parses the query as a boolean expression, searches it in the index file
+// and returns a result set ordered by score.
+// Terms separated by whitespace must all match (AND); the operators
+// AND, OR and NOT must be written in upper case. NOT or a leading '-' excludes
+// all results of the following term. Parentheses group terms.
+// Phrases, NEAR/n, patterns, boosts and fields work like with SearchOptions.Syntax.
+// AND binds stronger than OR, so `berlin (mauer OR wall) -museum` finds all results
+// that match "berlin" and at least one of "mauer" and "wall" but not "museum".
+// Each word is segmented and normalized like the query of Search.
+// See Search for the meaning of maxResults.
+func (f *File) SearchQuery(query []byte, maxResults int) ([]Result, error) {
+	return f.SearchWithOptions(query, SearchOptions{Boolean: true, MaxResults: maxResults})
+}
+
+// node is a node of a parsed boolean query.
+type node interface {
+	// eval returns the results of the node and the termScore to use for them.
+	eval(s *searcher) ([]Result, termScore)
+	// explain returns the explanation of the score the node adds to the result with the ID.
+	explain(s *searcher, id ID) Explanation
+	String() string
+}
+
+// termNode matches all results that match each of its normalized segments.
+type termNode struct {
+	segments [][]byte
+	phrase   bool
+	prefix   bool   // the last segment is a prefix
+	boost    Score  // multiplies the scores; 0 means no boost
+	field    string // the field of the keys of the segments
+}
+
+// key returns the key of the segment with the given index.
+func (n termNode) key(idx int) []byte {
+	return fieldKey(n.field, n.segments[idx])
+}
+
+// keys returns the keys of all segments.
+func (n termNode) keys() [][]byte {
+	if len(n.field) == 0 {
+		return n.segments
+	}
+	keys := make([][]byte, len(n.segments))
+	for idx := range n.segments {
+		keys[idx] = n.key(idx)
+	}
+	return keys
+}
+
+func (n termNode) eval(s *searcher) ([]Result, termScore) {
+	if len(n.segments) == 1 {
+		results, score := n.lookup(s, 0)
+		return results, boosted(score, n.boost)
+	}
+	var qr = make(map[ID]Score)
+	for idx := range n.segments {
+		results, score := n.lookup(s, idx)
+		intersection(results, qr, s.maxResults, score)
+		if len(qr) == 0 {
+			return nil, resultScore
+		}
+	}
+	if n.phrase && s.positions != nil {
+		for id := range qr {
+			if len(phrasePositions(s.positions, id, n.keys())) == 0 {
+				delete(qr, id)
+			}
+		}
+	}
+	return resultsOf(qr), boosted(resultScore, n.boost)
+}
+
+// lookup returns the results of the segment with the given index.
+func (n termNode) lookup(s *searcher, idx int) ([]Result, termScore) {
+	switch {
+	case n.prefix && idx == len(n.segments)-1:
+		return s.lookupPrefix(n.key(idx))
+	case !n.phrase && len(n.segments) == 1 && s.maxDistance > 0:
+		return s.lookupFuzzy(n.field, n.segments[idx])
+	}
+	return s.lookup(n.key(idx))
+}
+
+func (n termNode) String() string {
+	text := string(bytes.Join(n.segments, []byte{' '}))
+	if n.phrase {
+		text = `"` + text + `"`
+	} else if n.prefix {
+		text += "*"
+	}
+	return fieldString(n.field) + text + boostString(n.boost)
+}
+
+// nearNode matches all results that match each of its terms
+// where the positions of neighboring terms differ by at most the given distance.
+// Without a positional index the distances are ignored.
+type nearNode struct {
+	terms     []termNode
+	distances []uint32 // distances[i] is the distance between terms[i] and terms[i+1]
+}
+
+func (n nearNode) eval(s *searcher) ([]Result, termScore) {
+	var qr = make(map[ID]Score)
+	for _, term := range n.terms {
+		results, score := term.eval(s)
+		intersection(results, qr, s.maxResults, score)
+		if len(qr) == 0 {
+			return nil, resultScore
+		}
+	}
+	if s.positions != nil {
+		for id := range qr {
+			for idx, distance := range n.distances {
+				left, right := n.terms[idx], n.terms[idx+1]
+				if !isNear(phrasePositions(s.positions, id, left.keys()), uint32(len(left.segments)),
+					phrasePositions(s.positions, id, right.keys()), uint32(len(right.segments)), distance) {
+					delete(qr, id)
+					break
+				}
+			}
+		}
+	}
+	return resultsOf(qr), resultScore
+}
+
+func (n nearNode) String() string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for idx, term := range n.terms {
+		if idx > 0 {
+			fmt.Fprintf(&b, " NEAR/%d ", n.distances[idx-1])
+		}
+		b.WriteString(term.String())
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// near returns the node matching left and right within the given distance.
+// Only terms without prefix can be combined by NEAR; other nodes are intersected.
+func near(left, right node, distance uint32) node {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	}
+	rightTerm, isTerm := right.(termNode)
+	if !isTerm || rightTerm.prefix {
+		return opNode{op: Intersection, children: []node{left, right}}
+	}
+	switch l := left.(type) {
+	case termNode:
+		if l.prefix {
+			break
+		}
+		return nearNode{terms: []termNode{l, rightTerm}, distances: []uint32{distance}}
+	case nearNode:
+		return nearNode{
+			terms:     append(l.terms[:len(l.terms):len(l.terms)], rightTerm),
+			distances: append(l.distances[:len(l.distances):len(l.distances)], distance),
+		}
+	}
+	return opNode{op: Intersection, children: []node{left, right}}
+}
+
+// opNode combines the results of its children by Union or Intersection.
+type opNode struct {
+	op       SetOperation
+	children []node
+	// fields marks the Union of a term in the searched fields (see expandFields).
+	// Its results form a single posting list that is not limited by maxResults,
+	// so a missed result is accounted by the caller like for a single field.
+	fields bool
+}
+
+func (n opNode) eval(s *searcher) ([]Result, termScore) {
+	var qr = make(map[ID]Score)
+	switch n.op {
+	case Union:
+		maxResults := s.maxResults
+		if n.fields {
+			maxResults = 0
+		}
+		for _, child := range n.children {
+			if _, isNot := child.(notNode); isNot {
+				continue // excluding from a union has no meaning
+			}
+			results, score := child.eval(s)
+			union(results, qr, maxResults, score)
+		}
+	case Intersection:
+		var excluded []node
+		for _, child := range n.children {
+			if not, isNot := child.(notNode); isNot {
+				excluded = append(excluded, not.child)
+				continue
+			}
+			results, score := child.eval(s)
+			intersection(results, qr, s.maxResults, score)
+			if len(qr) == 0 {
+				return nil, resultScore
+			}
+		}
+		for _, child := range excluded {
+			results, _ := child.eval(s)
+			difference(results, qr)
+		}
+	}
+	return resultsOf(qr), resultScore
+}
+
+func (n opNode) String() string {
+	var op = " AND "
+	if n.op == Union {
+		op = " OR "
+	}
+	children := make([]string, len(n.children))
+	for idx, child := range n.children {
+		children[idx] = child.String()
+	}
+	return "(" + strings.Join(children, op) + ")"
+}
+
+// notNode excludes the results of its child from the results of its parent.
+// It matches nothing on its own.
+type notNode struct {
+	child node
+}
+
+func (n notNode) eval(*searcher) ([]Result, termScore) {
+	return nil, resultScore
+}
+
+func (n notNode) String() string {
+	return "-" + n.child.String()
+}
+
+type tokenKind uint8
+
+const (
+	tokenWord tokenKind = iota
+	tokenPhrase
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+	tokenNear
+	tokenPattern
+	tokenRegexp
+)
+
+type token struct {
+	kind     tokenKind
+	text     []byte
+	distance uint32 // of tokenNear
+	prefix   bool   // of tokenWord ending with '*'
+	boost    Score  // of a word, phrase or pattern ending with '^' and a number
+	field    string // of a word, phrase or pattern starting with a field name and ':'
+}
+
+// tokenize splits the query into words, phrases, operators and parentheses.
+// If boolean is false, only phrases and NEAR/n are recognized
+// and all other text is split into words.
+func tokenize(query []byte, boolean bool) []token {
+	var tokens []token
+	for len(query) > 0 {
+		r, width := utf8.DecodeRune(query)
+		switch {
+		case unicode.IsSpace(r):
+			query = query[width:]
+		case r == '"':
+			query = query[width:]
+			end := bytes.IndexByte(query, '"')
+			if end < 0 {
+				end = len(query)
+			}
+			t := token{kind: tokenPhrase, text: query[:end], field: takeField(&tokens)}
+			query = query[end:]
+			if len(query) > 0 {
+				query = query[1:] // closing quote
+			}
+			t.boost, query = trailingBoost(query, boolean)
+			tokens = append(tokens, t)
+		case r == '/' && bytes.IndexByte(query[width:], '/') > 0:
+			end := width + bytes.IndexByte(query[width:], '/')
+			t := token{kind: tokenRegexp, text: query[width:end], field: takeField(&tokens)}
+			t.boost, query = trailingBoost(query[end+1:], boolean)
+			tokens = append(tokens, t)
+		case boolean && r == '(':
+			tokens = append(tokens, token{kind: tokenOpen})
+			query = query[width:]
+		case boolean && r == ')':
+			tokens = append(tokens, token{kind: tokenClose})
+			query = query[width:]
+		case boolean && r == '-' && len(query) > width && isNegatable(query[width:]):
+			tokens = append(tokens, token{kind: tokenNot})
+			query = query[width:]
+		case fieldEnd(query) > 0:
+			// the field applies to the following phrase or regular expression (see takeField)
+			end := fieldEnd(query)
+			tokens = append(tokens, token{kind: tokenWord, field: string(query[:end-1])})
+			query = query[end:]
+		default:
+			end := wordEnd(query, boolean)
+			tokens = append(tokens, wordToken(query[:end], boolean))
+			query = query[end:]
+		}
+	}
+	return tokens
+}
+
+// wordEnd returns the length of the word at the start of the query.
+func wordEnd(query []byte, boolean bool) int {
+	_, end := utf8.DecodeRune(query)
+	for end < len(query) && !isWordEnd(query[end:], boolean) {
+		_, width := utf8.DecodeRune(query[end:])
+		end += width
+	}
+	return end
+}
+
+// fieldEnd returns the length of a field name and ':' at the start of the query,
+// if a phrase or regular expression follows; otherwise 0.
+func fieldEnd(query []byte) int {
+	idx := bytes.IndexByte(query, ':')
+	if idx <= 0 || idx+1 >= len(query) || (query[idx+1] != '"' && query[idx+1] != '/') ||
+		!isFieldName(query[:idx]) {
+		return 0
+	}
+	return idx + 1
+}
+
+// takeField removes a preceding token consisting only of a field name and ':'
+// from the tokens and returns its field, so it applies to the following phrase or pattern.
+func takeField(tokens *[]token) string {
+	n := len(*tokens)
+	if n == 0 {
+		return ""
+	}
+	last := (*tokens)[n-1]
+	if last.kind != tokenWord || len(last.text) > 0 || len(last.field) == 0 {
+		return ""
+	}
+	*tokens = (*tokens)[:n-1]
+	return last.field
+}
+
+// trailingBoost returns the boost at the start of the query following
+// a phrase or regular expression, like `^2`, and the rest of the query.
+func trailingBoost(query []byte, boolean bool) (Score, []byte) {
+	if len(query) == 0 || query[0] != '^' {
+		return 0, query
+	}
+	end := wordEnd(query, boolean)
+	if _, boost := cutBoost(query[:end]); boost > 0 {
+		return boost, query[end:]
+	}
+	return 0, query
+}
+
+// wordToken returns the token of the word, which may be an operator.
+func wordToken(word []byte, boolean bool) token {
+	if bytes.HasPrefix(word, []byte("NEAR/")) {
+		if distance, err := strconv.ParseUint(string(word[len("NEAR/"):]), 10, 32); err == nil {
+			return token{kind: tokenNear, distance: uint32(distance)}
+		}
+	}
+	if boolean {
+		switch string(word) {
+		case "AND":
+			return token{kind: tokenAnd}
+		case "OR":
+			return token{kind: tokenOr}
+		case "NOT":
+			return token{kind: tokenNot}
+		}
+	}
+	word, boost := cutBoost(word)
+	var field string
+	if idx := bytes.IndexByte(word, ':'); idx > 0 && isFieldName(word[:idx]) {
+		field, word = string(word[:idx]), word[idx+1:]
+	}
+	if len(word) > 1 && bytes.IndexByte(word, '*') == len(word)-1 && !isPattern(word[:len(word)-1]) {
+		return token{kind: tokenWord, text: word[:len(word)-1], prefix: true, boost: boost, field: field}
+	}
+	if isPattern(word) {
+		return token{kind: tokenPattern, text: word, boost: boost, field: field}
+	}
+	return token{kind: tokenWord, text: word, boost: boost, field: field}
+}
+
+// isWordEnd reports whether a word ends before the given text.
+func isWordEnd(text []byte, boolean bool) bool {
+	r, _ := utf8.DecodeRune(text)
+	return unicode.IsSpace(r) || r == '"' || (boolean && (r == '(' || r == ')'))
+}
+
+// isNegatable reports whether a leading '-' negates the given text.
+func isNegatable(text []byte) bool {
+	r, _ := utf8.DecodeRune(text)
+	return !unicode.IsSpace(r) && r != ')'
+}
+
+// parser is a recursive descent parser of boolean queries:
+//
+//	or      = and { "OR" and }
+//	and     = unary { [ "AND" ] unary }
+//	unary   = ( "NOT" | "-" ) unary | near
+//	near    = primary { "NEAR/n" primary }
+//	primary = "(" or ")" | phrase | word
+//
+// Syntax errors are tolerated: missing operands and unbalanced parentheses are ignored.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseQuery parses the boolean query.
+// It returns nil if the query contains no relevant segments.
+func parseQuery(query []byte) node {
+	p := parser{tokens: tokenize(query, true)}
+	var children []node
+	for p.pos < len(p.tokens) {
+		if n := p.parseOr(); n != nil {
+			children = append(children, n)
+		}
+		if p.pos < len(p.tokens) {
+			p.pos++ // skip unbalanced ")" or trailing operator
+		}
+	}
+	return combine(Intersection, children)
+}
+
+func (p *parser) peek() (tokenKind, bool) {
+	if p.pos >= len(p.tokens) {
+		return 0, false
+	}
+	return p.tokens[p.pos].kind, true
+}
+
+func (p *parser) parseOr() node {
+	var children []node
+	for {
+		if n := p.parseAnd(); n != nil {
+			children = append(children, n)
+		}
+		if kind, ok := p.peek(); !ok || kind != tokenOr {
+			break
+		}
+		p.pos++
+	}
+	return combine(Union, children)
+}
+
+func (p *parser) parseAnd() node {
+	var children []node
+	for {
+		kind, ok := p.peek()
+		if !ok || kind == tokenOr || kind == tokenClose {
+			break
+		}
+		if kind == tokenAnd {
+			p.pos++
+			continue
+		}
+		if n := p.parseUnary(); n != nil {
+			children = append(children, n)
+		}
+	}
+	return combine(Intersection, children)
+}
+
+func (p *parser) parseUnary() node {
+	if kind, _ := p.peek(); kind == tokenNot {
+		p.pos++
+		if kind, ok := p.peek(); !ok || kind == tokenOr || kind == tokenClose {
+			return nil
+		}
+		if n := p.parseUnary(); n != nil {
+			if not, isNot := n.(notNode); isNot {
+				return not.child // double negation
+			}
+			return notNode{child: n}
+		}
+		return nil
+	}
+	return p.parseNear()
+}
+
+func (p *parser) parseNear() node {
+	n := p.parsePrimary()
+	for {
+		kind, ok := p.peek()
+		if !ok || kind != tokenNear {
+			return n
+		}
+		distance := p.tokens[p.pos].distance
+		p.pos++
+		if kind, ok := p.peek(); !ok || (kind != tokenWord && kind != tokenPhrase &&
+			kind != tokenPattern && kind != tokenRegexp && kind != tokenOpen) {
+			return n
+		}
+		n = near(n, p.parsePrimary(), distance)
+	}
+}
+
+func (p *parser) parsePrimary() node {
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case tokenOpen:
+		n := p.parseOr()
+		if kind, ok := p.peek(); ok && kind == tokenClose {
+			p.pos++
+		}
+		return n
+	case tokenWord, tokenPhrase:
+		segments := normalizeQuery(t.text)
+		if len(segments) == 0 {
+			return nil
+		}
+		return termNode{segments: segments, phrase: t.kind == tokenPhrase, prefix: t.prefix,
+			boost: t.boost, field: t.field}
+	case tokenPattern:
+		return withField(withBoost(newWildcardNode(t.text), t.boost), t.field)
+	case tokenRegexp:
+		return withField(withBoost(newRegexpNode(t.text), t.boost), t.field)
+	}
+	return nil
+}
+
+// literalTerms returns the terms of a query without syntax (see Search):
+// each relevant segment is a term.
+func literalTerms(query []byte) []node {
+	var terms []node
+	for _, segment := range normalizeQuery(query) {
+		terms = append(terms, termNode{segments: [][]byte{segment}})
+	}
+	return terms
+}
+
+// parseTerms returns the terms of a query using the syntax of SearchOptions.Syntax.
+// Each relevant segment is a term, except for text in double quotes,
+// which is a single phrase term, and terms combined by NEAR/n.
+// The last segment of a word ending with '*' is a prefix and
+// wildcard patterns and regular expressions are single terms.
+func parseTerms(query []byte) []node {
+	var terms []node
+	var nearToken *token
+	for _, t := range tokenize(query, false) {
+		var next []node
+		switch t.kind {
+		case tokenNear:
+			nearToken = &token{kind: t.kind, distance: t.distance}
+			continue
+		case tokenWord:
+			segments := normalizeQuery(t.text)
+			for idx, segment := range segments {
+				next = append(next, termNode{segments: [][]byte{segment},
+					prefix: t.prefix && idx == len(segments)-1, boost: t.boost, field: t.field})
+			}
+		case tokenPhrase:
+			if segments := normalizeQuery(t.text); len(segments) > 0 {
+				next = append(next, termNode{segments: segments, phrase: true, boost: t.boost, field: t.field})
+			}
+		case tokenPattern:
+			if n := newWildcardNode(t.text); n != nil {
+				next = append(next, withField(withBoost(n, t.boost), t.field))
+			}
+		case tokenRegexp:
+			if n := newRegexpNode(t.text); n != nil {
+				next = append(next, withField(withBoost(n, t.boost), t.field))
+			}
+		}
+		if len(next) == 0 {
+			continue
+		}
+		if nearToken != nil && len(terms) > 0 {
+			terms[len(terms)-1] = near(terms[len(terms)-1], next[0], nearToken.distance)
+			next = next[1:]
+		}
+		nearToken = nil
+		terms = append(terms, next...)
+	}
+	return terms
+}
+
+// combine returns the node combining the children by the SetOperation.
+func combine(op SetOperation, children []node) node {
+	switch len(children) {
+	case 0:
+		return nil
+	case 1:
+		return children[0]
+	}
+	return opNode{op: op, children: children}
+}
+
+// normalizeQuery returns the relevant normalized segments of the query.
+func normalizeQuery(query []byte) [][]byte {
+	var segments [][]byte
+	for _, segment := range uniseg.Segments(query) {
+		if element := normalizeSegment(segment); len(element) > 0 {
+			segments = append(segments, element)
+		}
+	}
+	return segments
+}
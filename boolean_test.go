@@ -0,0 +1,99 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	var tests = map[string]string{
+		"":                                "<nil>",
+		"Berlin":                          "berlin",
+		"berlin mauer":                    "(berlin AND mauer)",
+		"berlin AND mauer":                "(berlin AND mauer)",
+		"berlin OR mauer museum":          "(berlin OR (mauer AND museum))",
+		"berlin (mauer OR wall) -museum":  "(berlin AND (mauer OR wall) AND -museum)",
+		"NOT berlin":                      "-berlin",
+		"- berlin":                        "berlin",
+		"-(a OR b) c":                     "(-(a OR b) AND c)",
+		"--berlin":                        "berlin",
+		`"New York" city`:                 `("new york" AND city)`,
+		`"New York`:                       `"new york"`,
+		"(berlin OR":                      "berlin",
+		"berlin) mauer":                   "(berlin AND mauer)",
+		"e-mail":                          "e mail",
+		"OR AND NOT":                      "<nil>",
+		"a NEAR/3 b":                      "(a NEAR/3 b)",
+		`a NEAR/3 "b c" NEAR/1 d`:         `(a NEAR/3 "b c" NEAR/1 d)`,
+		"a NEAR/3 (b OR c)":               "(a AND (b OR c))",
+		"NEAR/3 a":                        "a",
+		"a NEAR/3":                        "a",
+		"a NEAR/x b":                      "(a AND near x AND b)",
+		"Berl* -mau*":                     "(berl* AND -mau*)",
+		"a NEAR/3 b*":                     "(a AND b*)",
+		"*":                               "<nil>",
+		"*Burg m??er":                     "(*burg AND m??er)",
+		"Straße?":                         "strasse",
+		"st*ße":                           "st*sse",
+		"/m[ae]i?er/ OR x":                "(/m[ae]i?er/ OR x)",
+		"/m[ae/":                          "<nil>",
+		"** ??":                           "<nil>",
+		`berlin^3 "New York"^0.5 m*r^2`:   `(berlin^3 AND "new york"^0.5 AND m*r^2)`,
+		"a^x b^-1":                        "(a x AND b 1)",
+		`title:Berlin^2 title:"New York"`: `(title:berlin^2 AND title:"new york")`,
+		"Title:berlin 10:30":              "(title berlin AND 10 30)",
+	}
+
+	for input, expected := range tests {
+		var got = "<nil>"
+		if n := parseQuery([]byte(input)); n != nil {
+			got = n.String()
+		}
+		if got != expected {
+			t.Errorf("parseQuery(%s) = %s; expected %s", input, got, expected)
+		}
+	}
+}
+
+func TestParseTerms(t *testing.T) {
+	var tests = map[string]string{
+		"":                          "[]",
+		"berlin (mauer OR -museum)": "[berlin mauer or museum]",
+		`"New York" city`:           `["new york" city]`,
+		"100jähriges Jubiläum":      "[100 jaehriges jubilaeum]",
+		"new NEAR/2 york city":      "[(new NEAR/2 york) city]",
+		"new NEAR/2 100jähriges":    "[(new NEAR/2 100) jaehriges]",
+		"berl* 100jähr*":            "[berl* 100 jaehr*]",
+		"berlin^2 e-mail^1.5 /x/^3": "[berlin^2 e^1.5 mail^1.5 /x/^3]",
+		"a^2 NEAR/1 b":              "[(a^2 NEAR/1 b)]",
+	}
+
+	for input, expected := range tests {
+		if got := fmt.Sprint(parseTerms([]byte(input))); got != expected {
+			t.Errorf("parseTerms(%s) = %s; expected %s", input, got, expected)
+		}
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("Berlin Mauer")},
+		{ID: 2, Text: []byte("Berlin Wall Museum")},
+		{ID: 3, Text: []byte("Berlin Wall")},
+		{ID: 4, Text: []byte("Hamburg Wall")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := f.SearchQuery([]byte("berlin (mauer OR wall) -museum"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := resultIDs(results); !equalIDs(ids, []ID{1, 3}) {
+		t.Errorf("SearchQuery = %v; expected [1 3]", ids)
+	}
+}
@@ -0,0 +1,98 @@
+package minsearch
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strconv"
+)
+
+// WeightedTerm is a part of a query whose score is multiplied by Weight.
+type WeightedTerm struct {
+	// Query is searched like the query of Search and can contain multiple segments.
+	Query []byte
+	// Weight multiplies the score each segment of Query adds to a result.
+	// If Weight <= 0, the weight is 1.
+	Weight float32
+}
+
+// SearchWeighted works like SearchWithOptions, but the query consists
+// of the given terms, whose scores are multiplied by their weights.
+// If SearchOptions.Syntax is set, boosts inside the query of a term are multiplied by its weight.
+// SearchOptions.Boolean is ignored.
+func (f *File) SearchWeighted(terms []WeightedTerm, opts SearchOptions) ([]Result, error) {
+	opts.Boolean = false
+	var nodes []node
+	for _, term := range terms {
+		for _, n := range queryTerms(term.Query, opts) {
+			nodes = append(nodes, withBoost(n, term.Weight))
+		}
+	}
+	results, _, err := f.searchTerms(context.Background(), nodes, opts)
+	return results, err
+}
+
+// withBoost returns the node whose score is multiplied by the boost.
+// Only the scores of terms and patterns can be boosted; if boost <= 0 the node is unchanged.
+func withBoost(n node, boost Score) node {
+	if boost <= 0 || boost == 1 {
+		return n
+	}
+	switch b := n.(type) {
+	case termNode:
+		b.boost = multiplyBoost(b.boost, boost)
+		return b
+	case patternNode:
+		b.boost = multiplyBoost(b.boost, boost)
+		return b
+	case nearNode:
+		terms := make([]termNode, len(b.terms))
+		for idx, term := range b.terms {
+			term.boost = multiplyBoost(term.boost, boost)
+			terms[idx] = term
+		}
+		b.terms = terms
+		return b
+	}
+	return n
+}
+
+// multiplyBoost returns the product of the boosts, where 0 means no boost.
+func multiplyBoost(a, b Score) Score {
+	if a == 0 {
+		return b
+	}
+	return a * b
+}
+
+// boosted returns the termScore that multiplies the scores by the boost.
+func boosted(score termScore, boost Score) termScore {
+	if boost == 0 || boost == 1 {
+		return score
+	}
+	return func(r Result) Score {
+		return boost * score(r)
+	}
+}
+
+// boostString returns the query syntax of the boost.
+func boostString(boost Score) string {
+	if boost == 0 {
+		return ""
+	}
+	return "^" + strconv.FormatFloat(float64(boost), 'g', -1, 32)
+}
+
+// cutBoost splits a trailing boost like `^3` or `^0.5` from the word.
+// If the word has no valid positive boost, it's returned unchanged with boost 0.
+func cutBoost(word []byte) ([]byte, Score) {
+	idx := bytes.LastIndexByte(word, '^')
+	if idx < 0 {
+		return word, 0
+	}
+	boost, err := strconv.ParseFloat(string(word[idx+1:]), 32)
+	if err != nil || !(boost > 0) || math.IsInf(boost, 1) {
+		return word, 0
+	}
+	return word[:idx], Score(boost)
+}
@@ -0,0 +1,54 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearchBoost(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	pairs := []Pair{
+		{ID: 1, Text: []byte("berlin mauer")},
+		{ID: 2, Text: []byte("berlin")},
+		{ID: 3, Text: []byte("mauer")},
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := f.Search([]byte("berlin mauer"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := make(map[ID]Score)
+	for _, r := range plain {
+		scores[r.ID] = r.Score
+	}
+
+	for _, setOp := range []SetOperation{Union, Intersection} {
+		results, err := f.SearchWithOptions([]byte("berlin^3 mauer"), SearchOptions{SetOperation: setOp, Syntax: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		weighted, err := f.SearchWeighted([]WeightedTerm{{Query: []byte("berlin"), Weight: 3}, {Query: []byte("mauer")}},
+			SearchOptions{SetOperation: setOp})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(results) != fmt.Sprint(weighted) {
+			t.Errorf("Search(%d) = %v; SearchWeighted = %v", setOp, results, weighted)
+		}
+		if setOp == Union {
+			if results[0].ID != 1 || results[1].ID != 2 || results[2].ID != 3 {
+				t.Errorf("Search(berlin^3 mauer) = %v; expected IDs 1, 2, 3", results)
+			}
+			if expected := 3 * scores[2]; results[1].Score != expected {
+				t.Errorf("boosted score of ID 2 = %v; expected %v", results[1].Score, expected)
+			}
+		} else if len(results) != 1 || results[0].Score <= scores[1] {
+			t.Errorf("Search(berlin^3 mauer, Intersection) = %v; expected a higher score than %v", results, scores[1])
+		}
+	}
+}
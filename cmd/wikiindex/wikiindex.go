@@ -17,11 +17,21 @@ func main() {
 	var fullText bool
 	var idLimit int
 	var noSync bool
+	var positions bool
+	var reversedKeys bool
+	var lengthBoost bool
+	var analyzer string
+	var bm25 bool
 
 	flag.StringVar(&filename, "filename", "", "Filename of the MediaWiki xml.bz2 file to index.")
 	flag.BoolVar(&fullText, "fullText", false, "Index also full text.")
 	flag.IntVar(&idLimit, "idLimit", -1, "If idLimit>0 only the highest idLimit scores will be indexed per key.")
 	flag.BoolVar(&noSync, "noSync", false, "If nosync=true indexing will be much faster but data can be lost if system crashes.")
+	flag.BoolVar(&positions, "positions", false, "Create a positional index for phrase queries when creating the index file.")
+	flag.BoolVar(&reversedKeys, "reversedKeys", false, "Store reversed keys for fast suffix patterns when creating the index file.")
+	flag.BoolVar(&lengthBoost, "lengthBoost", false, "Store the text length of each page as its boost, so short stub pages rank below the main articles.")
+	flag.StringVar(&analyzer, "analyzer", "", "Stem the words using the analyzer \"german\" or \"english\" when creating the index file.")
+	flag.BoolVar(&bm25, "bm25", false, "Store the term frequencies for BM25 ranking when creating the index file, which is then always searched with BM25.")
 	flag.Parse()
 
 	if flag.NFlag() < 1 || len(filename) == 0 {
@@ -35,7 +45,25 @@ func main() {
 		log.Fatal(fErr)
 	}
 
-	index, openErr := minsearch.Open(filename+".idx", noSync)
+	options := minsearch.Options{
+		NoSync:       noSync,
+		Positions:    positions,
+		ReversedKeys: reversedKeys,
+	}
+	if bm25 {
+		options.Scorer = minsearch.BM25{K1: 1.2, B: 0.75}
+	}
+	switch analyzer {
+	case "":
+	case "german":
+		options.Analyzer = minsearch.GermanAnalyzer
+	case "english":
+		options.Analyzer = minsearch.EnglishAnalyzer
+	default:
+		log.Fatalf("unknown analyzer %q", analyzer)
+	}
+
+	index, openErr := minsearch.OpenWithOptions(filename+".idx", options)
 
 	if openErr != nil {
 		log.Fatal(openErr)
@@ -51,6 +79,7 @@ func main() {
 
 	var batchPairsTitles []minsearch.Pair
 	var batchPairsTexts []minsearch.Pair
+	batchBoosts := make(map[minsearch.ID]float32)
 
 	lastID, lastIDErr := index.LastID()
 	lastPos := uint64(lastID)
@@ -91,8 +120,13 @@ func main() {
 			}
 
 			batchPairsTitles = append(batchPairsTitles, minsearch.Pair{
-				ID:   minsearch.ID(page.ID),
-				Text: []byte(page.Title)})
+				ID:    minsearch.ID(page.ID),
+				Text:  []byte(page.Title),
+				Field: "title"})
+
+			if lengthBoost && len(page.Revisions) > 0 && len(page.Redir.Title) == 0 {
+				batchBoosts[minsearch.ID(page.ID)] = float32(len(page.Revisions[0].Text))
+			}
 
 			if fullText {
 
@@ -112,6 +146,11 @@ func main() {
 				index.IndexBatch(batchPairsTexts, idLimit)
 				batchPairsTexts = batchPairsTexts[:0]
 
+				if err := index.SetBoosts(batchBoosts); err != nil {
+					log.Fatal(err)
+				}
+				batchBoosts = make(map[minsearch.ID]float32)
+
 				if err := index.SetLastID(uint32(page.ID)); err != nil {
 					log.Fatal(err)
 				}
@@ -124,6 +163,10 @@ func main() {
 	index.IndexBatch(batchPairsTitles, 0)
 	index.IndexBatch(batchPairsTexts, idLimit)
 
+	if err := index.SetBoosts(batchBoosts); err != nil {
+		log.Fatal(err)
+	}
+
 	if updateErr := index.UpdateStatistics(); updateErr != nil {
 		log.Fatal(updateErr)
 	}
@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/tim-st/go-minsearch"
@@ -14,37 +16,135 @@ func main() {
 	var filename string
 	var query string
 	var limit int
+	var offset int
+	var after string
 	var intersection bool
+	var difference bool
+	var bm25 bool
+	var syntax bool
+	var boolean bool
+	var fuzzy int
+	var maxResults int
+	var minShouldMatch string
+	var fieldWeights string
+	var boostWeight float64
+	var complete int
+	var explain bool
 
 	flag.StringVar(&filename, "filename", "", "Filename of the index file to use.")
 	flag.StringVar(&query, "query", "", "The text to search in the index file.")
 	flag.IntVar(&limit, "limit", -1, "Limit the output of the result to the given number.")
+	flag.IntVar(&offset, "offset", 0, "Skip the given number of results.")
+	flag.StringVar(&after, "after", "", "Continue with the results after the given cursor printed by a previous search.")
 	flag.BoolVar(&intersection, "intersection", false, "true = intersection set; false = union set")
+	flag.BoolVar(&difference, "difference", false, "Exclude the results of all but the first word of the query from its results.")
+	flag.BoolVar(&bm25, "bm25", false, "Rank the results using BM25 (the index file must be created by wikiindex -bm25, which is searched with BM25 anyway).")
+	flag.BoolVar(&syntax, "syntax", false, "Parse phrases, NEAR/n, patterns, boosts and fields like \"title:berlin^2 mau*\" in the query.")
+	flag.BoolVar(&boolean, "boolean", false, "Parse the query as boolean expression like \"berlin (mauer OR wall) -museum\".")
+	flag.IntVar(&fuzzy, "fuzzy", 0, "If fuzzy>0 also words with an edit distance up to fuzzy are found.")
+	flag.StringVar(&minShouldMatch, "minShouldMatch", "", "Only find results that match at least the given number (like 2) or percentage (like 75%) of the words.")
+	flag.StringVar(&fieldWeights, "fieldWeights", "", "Weight the fields like \"title:5,:1\" where the empty name is the full text.")
+	flag.Float64Var(&boostWeight, "boostWeight", 0, "If boostWeight>0 weight the stored boosts of the pages logarithmically with the given weight; otherwise multiply the scores by them.")
+	flag.IntVar(&maxResults, "maxResults", 0, "If maxResults>0 limit the temporary results during the search to the given number.")
+	flag.IntVar(&complete, "complete", 0, "If complete>0 print up to complete completions of the last word of the query instead of searching.")
+	flag.BoolVar(&explain, "explain", false, "Print the explanation of the score below each result (requires -limit).")
 	flag.Parse()
 
 	if flag.NFlag() < 2 || len(filename) == 0 || len(query) == 0 {
 		flag.PrintDefaults()
 		return
 	}
+	if explain && limit <= 0 {
+		log.Fatal("-explain requires -limit, because each result is explained on its own")
+	}
 
 	if index, openErr := minsearch.Open(filename, true); openErr == nil {
+		if complete > 0 {
+			completions, completeErr := index.Complete([]byte(query), complete)
+			if completeErr != nil {
+				log.Fatal(completeErr)
+			}
+			for _, completion := range completions {
+				fmt.Println(completion)
+			}
+			return
+		}
+
 		start := time.Now()
-		var setOp = minsearch.Union
+		var opts = minsearch.SearchOptions{
+			Syntax:      syntax,
+			Boolean:     boolean,
+			MaxResults:  maxResults,
+			MaxDistance: fuzzy,
+			Offset:      offset,
+			Limit:       limit,
+			After:       minsearch.Cursor(after),
+		}
 		if intersection {
-			setOp = minsearch.Intersection
+			opts.SetOperation = minsearch.Intersection
+		} else if difference {
+			opts.SetOperation = minsearch.Difference
+		}
+		if percent := strings.TrimSuffix(minShouldMatch, "%"); len(percent) < len(minShouldMatch) {
+			var err error
+			if opts.MinShouldMatchPercent, err = strconv.Atoi(percent); err != nil {
+				log.Fatalf("invalid -minShouldMatch %q: %v", minShouldMatch, err)
+			}
+		} else if len(minShouldMatch) > 0 {
+			var err error
+			if opts.MinShouldMatch, err = strconv.Atoi(minShouldMatch); err != nil {
+				log.Fatalf("invalid -minShouldMatch %q: %v", minShouldMatch, err)
+			}
+		}
+		for _, fieldWeight := range strings.Split(fieldWeights, ",") {
+			if idx := strings.LastIndexByte(fieldWeight, ':'); idx >= 0 {
+				if weight, err := strconv.ParseFloat(fieldWeight[idx+1:], 32); err == nil {
+					if opts.FieldWeights == nil {
+						opts.FieldWeights = make(map[string]float32)
+					}
+					opts.FieldWeights[fieldWeight[:idx]] = float32(weight)
+				}
+			}
 		}
-		queryResults, queryErr := index.Search([]byte(query), setOp, 0)
+		if boostWeight > 0 {
+			opts.BoostFunc = minsearch.LogBoost(float32(boostWeight))
+		}
+		if bm25 {
+			opts.Scorer = minsearch.BM25{K1: 1.2, B: 0.75}
+		}
+		queryResults, next, queryErr := index.SearchPage([]byte(query), opts)
 		fmt.Printf("Took: %s\n", time.Since(start))
 
 		if queryErr != nil {
 			log.Fatal(queryErr)
 		}
 
+		if len(queryResults) == 0 {
+			if suggestions, err := index.Suggest([]byte(query), 1); err == nil && len(suggestions) > 0 {
+				fmt.Printf("Did you mean: %s\n", suggestions[0])
+			}
+		}
+
+		var explanations []minsearch.Explanation
+		if explain {
+			ids := make([]minsearch.ID, len(queryResults))
+			for idx, result := range queryResults {
+				ids[idx] = result.ID
+			}
+			var explainErr error
+			if explanations, explainErr = index.ExplainResults([]byte(query), ids, opts); explainErr != nil {
+				log.Fatal(explainErr)
+			}
+		}
+
 		for idx, result := range queryResults {
-			if limit > 0 && idx == limit {
-				break
+			fmt.Printf("Idx: %d; ID: %d; Score: %.15f\n", offset+idx, result.ID, result.Score)
+			if explain {
+				fmt.Print(explanations[idx])
 			}
-			fmt.Printf("Idx: %d; ID: %d; Score: %.15f\n", idx, result.ID, result.Score)
+		}
+		if len(next) > 0 {
+			fmt.Printf("Next: %s\n", next)
 		}
 
 	} else {
@@ -0,0 +1,114 @@
+package minsearch
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/boltdb/bolt"
+)
+
+// maxExpansions is the maximum number of keys a prefix of a query is expanded to.
+const maxExpansions = 1000
+
+// keyFreq is a key of the index together with its number of results.
+type keyFreq struct {
+	key     []byte
+	docFreq int
+}
+
+// topKeyList keeps the n keys with the most results
+// ordered by their number of results (descending) and by key.
+type topKeyList struct {
+	keys []keyFreq
+	n    int
+}
+
+func keyFreqLess(a, b keyFreq) bool {
+	if a.docFreq == b.docFreq {
+		return bytes.Compare(a.key, b.key) < 0
+	}
+	return a.docFreq > b.docFreq
+}
+
+// add adds the key with the given results, if it's among the top n keys.
+func (l *topKeyList) add(key, results []byte) {
+	l.addFreq(key, len(results)/sizeResult)
+}
+
+// addFreq adds the key with the given number of results, if it's among the top n keys.
+func (l *topKeyList) addFreq(key []byte, docFreq int) {
+	current := keyFreq{key: key, docFreq: docFreq}
+	if l.n <= 0 || (len(l.keys) == l.n && !keyFreqLess(current, l.keys[l.n-1])) {
+		return
+	}
+	idx := sort.Search(len(l.keys), func(i int) bool { return keyFreqLess(current, l.keys[i]) })
+	if len(l.keys) < l.n {
+		l.keys = append(l.keys, keyFreq{})
+	}
+	copy(l.keys[idx+1:], l.keys[idx:])
+	l.keys[idx] = current
+}
+
+// topKeys returns up to n keys starting with prefix
+// ordered by their number of results (descending) and by key.
+// Only keys of the field of the prefix (see fieldKey) are returned.
+// The keys are only valid during the transaction.
+func topKeys(bucket *bolt.Bucket, prefix []byte, n int) []keyFreq {
+	l := topKeyList{n: n}
+	c := bucket.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		if bytes.IndexByte(k[len(prefix):], fieldSeparator) < 0 {
+			l.add(k, v)
+		}
+	}
+	return l.keys
+}
+
+// Complete returns up to n completions of the last segment of the query
+// ordered by the number of results of the completed segment in all fields.
+// The other segments of the query are kept, so each completion is the
+// normalized query with the last segment replaced by an indexed segment starting with it.
+// If the File has an Analyzer (see Options.Analyzer), the indexed segments are reduced by it,
+// so the completions end with reduced segments, like "poni" for "pon".
+// The last segment is folded like the start of the indexed segments then,
+// so "schö" still completes to "schoneberg" (see GermanAnalyzer).
+func (f *File) Complete(query []byte, n int) ([]string, error) {
+	segments := normalizeQuery(query)
+	if len(segments) == 0 || n <= 0 {
+		return nil, nil
+	}
+	prefix := f.analyzer.fold(segments[len(segments)-1])
+	var head []byte
+	for _, segment := range segments[:len(segments)-1] {
+		head = append(head, segment...)
+		head = append(head, ' ')
+	}
+	var completions []string
+	err := f.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte{bucketWords})
+		fields := readFields(tx)
+		if len(fields) == 0 {
+			for _, key := range topKeys(bucket, prefix, n) {
+				completions = append(completions, string(head)+string(key.key))
+			}
+			return nil
+		}
+		// sum the results of the segments of all fields
+		docFreqs := make(map[string]int)
+		for _, field := range append([]string{""}, fields...) {
+			keyPrefix := fieldKey(field, prefix)
+			for _, key := range topKeys(bucket, keyPrefix, n) {
+				docFreqs[string(prefix)+string(key.key[len(keyPrefix):])] += key.docFreq
+			}
+		}
+		l := topKeyList{n: n}
+		for segment, docFreq := range docFreqs {
+			l.addFreq([]byte(segment), docFreq)
+		}
+		for _, key := range l.keys {
+			completions = append(completions, string(head)+string(key.key))
+		}
+		return nil
+	})
+	return completions, err
+}
@@ -0,0 +1,45 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("Berlin Mauer")},
+		{ID: 2, Text: []byte("Berliner Mauer")},
+		{ID: 3, Text: []byte("Berlin Bern")},
+		{ID: 4, Text: []byte("Bernau")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]string{
+		"Ber":        "[berlin berliner bern]",
+		"berli":      "[berlin berliner]",
+		"mauer berl": "[mauer berlin mauer berliner]",
+		"x":          "[]",
+	}
+	for query, expected := range tests {
+		completions, err := f.Complete([]byte(query), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(completions); got != expected {
+			t.Errorf("Complete(%s) = %s; expected %s", query, got, expected)
+		}
+	}
+
+	results, err := f.SearchWithOptions([]byte("berl* mauer"), SearchOptions{SetOperation: Intersection, Syntax: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := sortedIDs(results); !equalIDs(ids, []ID{1, 2}) {
+		t.Errorf("Search(berl* mauer) = %v; expected [1 2]", ids)
+	}
+}
@@ -0,0 +1,119 @@
+package minsearch
+
+import (
+	"github.com/boltdb/bolt"
+)
+
+// Delete removes all indexed (ID, Score) pairs and the boosts of the given IDs from the index.
+// Keys whose list of results becomes empty are removed as well.
+// All IDs are deleted in a single transaction.
+// If the File has no forward index (see Options.ForwardIndex)
+// all keys of the index must be scanned.
+func (f *File) Delete(ids ...ID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return f.db.Update(func(tx *bolt.Tx) error {
+		if err := deleteBoosts(tx, ids); err != nil {
+			return err
+		}
+		return deleteIDs(tx, ids)
+	})
+}
+
+func deleteIDs(tx *bolt.Tx, ids []ID) error {
+	idSet := make(map[ID]struct{}, len(ids))
+	for _, id := range ids {
+		idSet[id] = struct{}{}
+	}
+
+	lengths := tx.Bucket([]byte{bucketLengths})
+	positions := tx.Bucket([]byte{bucketPositions})
+	for _, field := range append([]string{""}, readFields(tx)...) {
+		c := readCollection(tx, field)
+		for id := range idSet {
+			if err := deleteLength(lengths, &c, field, id); err != nil {
+				return err
+			}
+		}
+		if err := writeCollection(tx, field, c); err != nil {
+			return err
+		}
+	}
+	if positions != nil {
+		for id := range idSet {
+			if err := deletePositions(positions, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	bucket := tx.Bucket([]byte{bucketWords})
+	reversed := tx.Bucket([]byte{bucketReversed})
+
+	if forward := tx.Bucket([]byte{bucketForward}); forward != nil {
+		for _, id := range ids {
+			key := idKey(id)
+			for _, segment := range decodeSegments(forward.Get(key)) {
+				if err := deleteFromKey(bucket, reversed, segment, idSet); err != nil {
+					return err
+				}
+			}
+			if err := forward.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// bolt doesn't allow to modify a bucket while iterating over it,
+	// so the affected keys are collected first.
+	var keys [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		for _, r := range asResults(v) {
+			if _, found := idSet[r.ID]; found {
+				keys = append(keys, append([]byte(nil), k...))
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := deleteFromKey(bucket, reversed, key, idSet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteFromKey removes the results of all IDs in idSet from the given key.
+// If no result is left, the key is deleted.
+func deleteFromKey(bucket, reversed *bolt.Bucket, key []byte, idSet map[ID]struct{}) error {
+	oldResultsData := bucket.Get(key)
+	oldResults := asResults(oldResultsData)
+	newResultsData := make([]byte, len(oldResultsData))
+	newResults := asResults(newResultsData)
+	n := 0
+	for _, r := range oldResults {
+		if _, found := idSet[r.ID]; !found {
+			newResults[n] = r
+			n++
+		}
+	}
+	if n == len(oldResults) {
+		return nil // nothing to delete
+	}
+	if n == 0 {
+		if reversed != nil {
+			if err := reversed.Delete(reverseKey(key)); err != nil {
+				return err
+			}
+		}
+		return bucket.Delete(key)
+	}
+	return bucket.Put(key, newResultsData[:n*sizeResult])
+}
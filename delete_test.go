@@ -0,0 +1,44 @@
+package minsearch
+
+import (
+	"testing"
+)
+
+func TestDelete(t *testing.T) {
+	for _, forwardIndex := range []bool{false, true} {
+		testDelete(t, Options{ForwardIndex: forwardIndex})
+	}
+}
+
+func testDelete(t *testing.T, options Options) {
+	f, cleanup := openTestFile(t, options)
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("Berlin Mauer")},
+		{ID: 2, Text: []byte("Berlin Museum")},
+		{ID: 3, Text: []byte("Hamburg Hafen")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Delete(2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := f.Search([]byte("berlin museum hamburg"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := resultIDs(results); !equalIDs(ids, []ID{1}) {
+		t.Errorf("%+v: Search after Delete = %v; expected [1]", options, ids)
+	}
+
+	if err := f.UpdateStatistics(); err != nil {
+		t.Fatal(err)
+	}
+	if keyCount, _ := f.KeyCount(); keyCount != 2 {
+		t.Errorf("%+v: KeyCount after Delete = %d; expected 2", options, keyCount)
+	}
+}
@@ -0,0 +1,72 @@
+package minsearch
+
+// editDistance returns the optimal string alignment distance of a and b,
+// which counts insertions, deletions, substitutions and transpositions
+// of adjacent runes. If the distance is greater than limit, limit+1 is returned.
+func editDistance(a, b []rune, limit int) int {
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return limit + 1
+	}
+	// rows i-2, i-1 and i of the distance matrix
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prev2[j-2]+1 < d {
+				d = prev2[j-2] + 1
+			}
+			curr[j] = d
+			if d < rowMin {
+				rowMin = d
+			}
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	if prev[len(b)] > limit {
+		return limit + 1
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// maxEditDistance returns the maximum edit distance allowed
+// for a segment with the given number of runes, which is at most limit
+// and at most 2: short segments would match too many other segments otherwise.
+func maxEditDistance(runeCount, limit int) int {
+	var allowed int
+	switch {
+	case runeCount < 3:
+		allowed = 0
+	case runeCount < 6:
+		allowed = 1
+	default:
+		allowed = 2
+	}
+	if limit < allowed {
+		return limit
+	}
+	return allowed
+}
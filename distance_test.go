@@ -0,0 +1,31 @@
+package minsearch
+
+import (
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	var tests = []struct {
+		a, b     string
+		limit    int
+		expected int
+	}{
+		{"", "", 2, 0},
+		{"berlin", "berlin", 2, 0},
+		{"berlin", "berln", 2, 1},
+		{"berlin", "bërlin", 2, 1},
+		{"berlin", "belrin", 2, 1},
+		{"berlin", "brelin", 2, 1},
+		{"berlin", "merlin", 2, 1},
+		{"berlin", "berliner", 2, 2},
+		{"berlin", "hamburg", 2, 3},
+		{"berlin", "b", 2, 3},
+		{"ca", "abc", 3, 3},
+	}
+
+	for _, test := range tests {
+		if got := editDistance([]rune(test.a), []rune(test.b), test.limit); got != test.expected {
+			t.Errorf("editDistance(%s, %s, %d) = %d; expected %d", test.a, test.b, test.limit, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package minsearch
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/boltdb/bolt"
+)
+
+// ErrInvalidBoost is returned if a static boost is negative, infinite or NaN.
+var ErrInvalidBoost = errors.New("minsearch: boosts must be finite numbers >= 0")
+
+// BoostFunc combines the score of a search result with the static boost of its ID
+// (see SetBoosts) and returns the final score of the result.
+type BoostFunc func(score Score, boost float32) Score
+
+// MultiplyBoost is the BoostFunc used if no other BoostFunc is set.
+// It multiplies the score by the boost.
+func MultiplyBoost(score Score, boost float32) Score {
+	return score * boost
+}
+
+// LogBoost returns a BoostFunc for boosts that are counts like page views or inbound links.
+// It multiplies the score by 1 + weight*ln(1+boost), so each additional
+// order of magnitude of the boost increases the score by about the same amount.
+func LogBoost(weight float32) BoostFunc {
+	return func(score Score, boost float32) Score {
+		return score * (1 + weight*float32(math.Log1p(float64(boost))))
+	}
+}
+
+// SetBoost stores the static boost of the given ID (see SetBoosts).
+func (f *File) SetBoost(id ID, boost float32) error {
+	return f.SetBoosts(map[ID]float32{id: boost})
+}
+
+// SetBoosts stores the static boosts of the given IDs in a single transaction.
+// A boost is a quality signal of the document with the ID, like its number of page views,
+// that is combined with the score of each search result with the ID
+// (see SearchOptions.BoostFunc). Boosts can be set before or after indexing
+// the ID and are kept when the ID is reindexed; Delete removes them.
+// Results of IDs without a boost keep their score.
+// If a boost is negative, infinite or NaN, ErrInvalidBoost is returned and no boost is stored.
+func (f *File) SetBoosts(boosts map[ID]float32) error {
+	for _, boost := range boosts {
+		if boost < 0 || math.IsInf(float64(boost), 0) || math.IsNaN(float64(boost)) {
+			return ErrInvalidBoost
+		}
+	}
+	return f.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte{bucketBoosts})
+		for id, boost := range boosts {
+			var boostBytes [4]byte
+			binary.LittleEndian.PutUint32(boostBytes[:], math.Float32bits(boost))
+			if err := bucket.Put(idKey(id), boostBytes[:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Boost returns the static boost of the given ID.
+// If the ID has no boost, ok is false.
+func (f *File) Boost(id ID) (boost float32, ok bool, err error) {
+	err = f.db.View(func(tx *bolt.Tx) error {
+		boost, ok = docBoost(tx.Bucket([]byte{bucketBoosts}), id)
+		return nil
+	})
+	return boost, ok, err
+}
+
+// docBoost returns the stored boost of the ID.
+func docBoost(bucket *bolt.Bucket, id ID) (float32, bool) {
+	data := bucket.Get(idKey(id))
+	if len(data) != 4 {
+		return 0, false
+	}
+	return math.Float32frombits(binary.LittleEndian.Uint32(data)), true
+}
+
+// boostsOf returns the bucket of the boosts, if any boost is stored; otherwise nil.
+func boostsOf(tx *bolt.Tx) *bolt.Bucket {
+	bucket := tx.Bucket([]byte{bucketBoosts})
+	if bucket == nil {
+		return nil
+	}
+	if k, _ := bucket.Cursor().First(); k == nil {
+		return nil
+	}
+	return bucket
+}
+
+// applyBoosts combines the scores of the result set with the boosts of their IDs.
+func applyBoosts(qr map[ID]Score, bucket *bolt.Bucket, boost BoostFunc) {
+	for id, score := range qr {
+		if b, ok := docBoost(bucket, id); ok {
+			qr[id] = boost(score, b)
+		}
+	}
+}
+
+// deleteBoosts removes the boosts of the given IDs.
+func deleteBoosts(tx *bolt.Tx, ids []ID) error {
+	bucket := tx.Bucket([]byte{bucketBoosts})
+	for _, id := range ids {
+		if err := bucket.Delete(idKey(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
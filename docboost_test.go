@@ -0,0 +1,87 @@
+package minsearch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBoosts(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	pairs := []Pair{
+		{ID: 1, Text: []byte("berlin")},
+		{ID: 2, Text: []byte("berlin hauptstadt deutschland")},
+		{ID: 3, Text: []byte("hauptstadt")},
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+	results, err := f.Search([]byte("berlin"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != 1 {
+		t.Fatalf("Search(berlin) = %v; expected the short text of ID 1 first", results)
+	}
+	scores := map[ID]Score{results[0].ID: results[0].Score, results[1].ID: results[1].Score}
+
+	if err := f.SetBoosts(map[ID]float32{1: 1, 2: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if boost, ok, err := f.Boost(2); err != nil || !ok || boost != 100 {
+		t.Errorf("Boost(2) = %v, %v, %v; expected 100, true, nil", boost, ok, err)
+	}
+	if _, ok, err := f.Boost(3); err != nil || ok {
+		t.Errorf("Boost(3) = _, %v, %v; expected false, nil", ok, err)
+	}
+
+	results, err = f.Search([]byte("berlin"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != 2 || results[0].Score != 100*scores[2] || results[1].Score != scores[1] {
+		t.Errorf("Search(berlin) with boosts = %v; expected ID 2 with score %v first", results, 100*scores[2])
+	}
+	for _, opts := range []SearchOptions{{Limit: 1}, {BoostFunc: LogBoost(1)}} {
+		results, err = f.SearchWithOptions([]byte("berlin"), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) == 0 || results[0].ID != 2 {
+			t.Errorf("SearchWithOptions(berlin, %+v) = %v; expected ID 2 first", opts, results)
+		}
+	}
+	iter, err := f.SearchIter([]byte("berlin"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := iter.Next(); !ok || r.ID != 2 {
+		t.Errorf("SearchIter(berlin).Next() = %v, %v; expected ID 2", r, ok)
+	}
+	iter.Close()
+
+	// boosts are kept by Reindex and removed by Delete
+	if err := f.Reindex([]Pair{{ID: 2, Text: []byte("berlin")}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := f.Boost(2); err != nil || !ok {
+		t.Errorf("Boost(2) after Reindex = _, %v, %v; expected true, nil", ok, err)
+	}
+	if err := f.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := f.Boost(2); err != nil || ok {
+		t.Errorf("Boost(2) after Delete = _, %v, %v; expected false, nil", ok, err)
+	}
+
+	// invalid boosts are rejected without storing the valid ones
+	for _, boost := range []float32{-1, float32(math.NaN()), float32(math.Inf(1)), float32(math.Inf(-1))} {
+		if err := f.SetBoosts(map[ID]float32{1: 2, 3: boost}); err != ErrInvalidBoost {
+			t.Errorf("SetBoosts(%v) returned %v; expected ErrInvalidBoost", boost, err)
+		}
+	}
+	if boost, ok, err := f.Boost(1); err != nil || !ok || boost != 1 {
+		t.Errorf("Boost(1) after invalid boosts = %v, %v, %v; expected 1, true, nil", boost, ok, err)
+	}
+}
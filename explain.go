@@ -0,0 +1,263 @@
+package minsearch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/boltdb/bolt"
+)
+
+// Explanation is a node of the tree that explains the score of a search result.
+type Explanation struct {
+	// Score is the score the node adds to its parent; the Score of the root is
+	// the score of the search result. It's 0 if the ID doesn't match the node.
+	Score Score
+	// Match reports whether the ID matches the node.
+	// A NOT node matches if its child doesn't match.
+	Match bool
+	// Description describes the node in the query syntax, like `title:berlin^2`,
+	// or names the set operation of its children, like `union`.
+	Description string
+	// Segment is the normalized segment of a node that looks up a single key.
+	Segment []byte
+	// Field is the field of Segment; the empty name is the default field.
+	Field string
+	// PostingScore is the Score stored for the ID in the results of Segment.
+	PostingScore Score
+	// DocFreq is the number of results of Segment (see QueryStats).
+	DocFreq int
+	// SetOperation combines the scores of the children of a set operation node.
+	SetOperation SetOperation
+	// Boost multiplies the scores of a term or is the static boost of the ID
+	// at the root (see SetBoosts); 0 means no boost.
+	Boost float32
+	// Children are the explanations of the parts of the node.
+	Children []Explanation
+}
+
+// String returns the tree of the explanation with one node per line.
+func (e Explanation) String() string {
+	var b strings.Builder
+	e.write(&b, 0)
+	return b.String()
+}
+
+func (e Explanation) write(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	if e.Match {
+		fmt.Fprintf(b, "%g", e.Score)
+	} else {
+		b.WriteString("no match")
+	}
+	b.WriteString(" " + e.Description)
+	if e.Segment != nil {
+		if e.Match {
+			fmt.Fprintf(b, " (posting score %g of %d results)", e.PostingScore, e.DocFreq)
+		} else {
+			fmt.Fprintf(b, " (%d results)", e.DocFreq)
+		}
+	}
+	b.WriteByte('\n')
+	for _, child := range e.Children {
+		child.write(b, depth+1)
+	}
+}
+
+// Explain returns the explanation of the score the ID gets from Search(query, Union, 0).
+// If the ID doesn't match the query, the Match of the explanation is false
+// and its children tell which parts of the query don't match.
+func (f *File) Explain(query []byte, id ID) (Explanation, error) {
+	return f.ExplainWithOptions(query, id, SearchOptions{})
+}
+
+// ExplainWithOptions works like Explain but explains the score the ID gets
+// from SearchWithOptions(query, opts). Offset, Limit and After are ignored.
+func (f *File) ExplainWithOptions(query []byte, id ID, opts SearchOptions) (Explanation, error) {
+	explanations, err := f.ExplainResults(query, []ID{id}, opts)
+	if err != nil {
+		return Explanation{}, err
+	}
+	return explanations[0], nil
+}
+
+// ExplainResults works like ExplainWithOptions but explains the scores of all given IDs,
+// like the results of a page of SearchPage, in a single read transaction.
+// The query is only searched once, but each ID is explained on its own,
+// so the number of IDs should be small.
+func (f *File) ExplainResults(query []byte, ids []ID, opts SearchOptions) ([]Explanation, error) {
+	if err := f.checkOptions(opts); err != nil {
+		return nil, err
+	}
+	var explanations []Explanation
+	err := f.db.View(func(tx *bolt.Tx) error {
+		s, terms, setOp := f.prepareSearch(tx, queryTerms(query, opts), opts)
+		qr, _, err := s.textSet(context.Background(), terms, setOp)
+		if err != nil {
+			return err
+		}
+		explanations = make([]Explanation, len(ids))
+		for idx, id := range ids {
+			explanations[idx] = s.explain(terms, setOp, qr, id)
+		}
+		return nil
+	})
+	return explanations, err
+}
+
+// explain returns the explanation of the score the ID gets from the terms,
+// whose result set without the static boosts is qr.
+func (s *searcher) explain(terms []node, setOp SetOperation, qr map[ID]Score, id ID) Explanation {
+	e := Explanation{Description: setOperationName(setOp), SetOperation: setOp}
+	if s.minMatch > 0 {
+		e.Description += " of at least " + strconv.Itoa(s.minMatch)
+	}
+	for _, term := range terms {
+		e.Children = append(e.Children, term.explain(s, id))
+	}
+	e.Score, e.Match = qr[id]
+	if !e.Match || s.boosts == nil {
+		return e
+	}
+	boost, ok := docBoost(s.boosts, id)
+	if !ok {
+		return e
+	}
+	return Explanation{
+		Score:       s.boost(e.Score, boost),
+		Match:       true,
+		Description: "static boost " + strconv.FormatFloat(float64(boost), 'g', -1, 32),
+		Boost:       boost,
+		Children:    []Explanation{e},
+	}
+}
+
+// setOperationName returns the name of the SetOperation used in explanations.
+func setOperationName(setOp SetOperation) string {
+	switch setOp {
+	case Intersection:
+		return "intersection"
+	case Difference:
+		return "difference"
+	}
+	return "union"
+}
+
+// explainResult returns the score the results add to the result with the ID,
+// if the results contain it.
+func explainResult(results []Result, score termScore, id ID) (Score, bool) {
+	for _, r := range results {
+		if r.ID == id {
+			return score(r), true
+		}
+	}
+	return 0, false
+}
+
+// explainKey returns the explanation of the score the results of the key add
+// to the result with the ID.
+func (s *searcher) explainKey(key []byte, id ID) Explanation {
+	results, score := s.lookup(key)
+	e := Explanation{
+		Description: fieldString(keyField(key)) + string(keySegment(key)),
+		Segment:     append([]byte(nil), keySegment(key)...),
+		Field:       keyField(key),
+		DocFreq:     len(results),
+	}
+	for _, r := range results {
+		if r.ID == id {
+			e.Score, e.Match, e.PostingScore = score(r), true, r.Score
+			break
+		}
+	}
+	return e
+}
+
+// explainMerged returns the explanation of the score the results of a lookup
+// merging the results of multiple keys add to the result with the ID.
+// Its children are the keys that contain the ID.
+func (s *searcher) explainMerged(id ID, lookup func() ([]Result, termScore)) Explanation {
+	var keys []Explanation
+	s.merged = func(key []byte, penalty Score) {
+		if k := s.explainKey(key, id); k.Match {
+			k.Score /= penalty
+			if penalty > 1 {
+				k.Description += "~" + strconv.Itoa(int(penalty)-1) // edit distance
+			}
+			keys = append(keys, k)
+		}
+	}
+	results, score := lookup()
+	s.merged = nil
+	e := Explanation{Children: keys}
+	e.Score, e.Match = explainResult(results, score, id)
+	return e
+}
+
+// isMerged reports whether the segment with the given index is looked up in multiple keys.
+func (n termNode) isMerged(s *searcher, idx int) bool {
+	switch {
+	case n.prefix && idx == len(n.segments)-1:
+		return true
+	case !n.phrase && len(n.segments) == 1 && s.maxDistance > 0:
+		return maxEditDistance(len([]rune(string(n.segments[idx]))), s.maxDistance) > 0
+	}
+	return false
+}
+
+func (n termNode) explain(s *searcher, id ID) Explanation {
+	results, score := n.eval(s)
+	if len(n.segments) == 1 && !n.isMerged(s, 0) {
+		e := s.explainKey(n.key(0), id)
+		e.Description, e.Boost = n.String(), n.boost
+		e.Score, e.Match = explainResult(results, score, id)
+		return e
+	}
+	e := Explanation{Description: n.String(), Field: n.field, Boost: n.boost}
+	e.Score, e.Match = explainResult(results, score, id)
+	for idx := range n.segments {
+		if !n.isMerged(s, idx) {
+			e.Children = append(e.Children, s.explainKey(n.key(idx), id))
+			continue
+		}
+		segment := s.explainMerged(id, func() ([]Result, termScore) { return n.lookup(s, idx) })
+		segment.Description = fieldString(n.field) + string(n.segments[idx])
+		if n.prefix {
+			segment.Description += "*"
+		}
+		e.Children = append(e.Children, segment)
+	}
+	return e
+}
+
+func (n nearNode) explain(s *searcher, id ID) Explanation {
+	results, score := n.eval(s)
+	e := Explanation{Description: n.String()}
+	e.Score, e.Match = explainResult(results, score, id)
+	for _, term := range n.terms {
+		e.Children = append(e.Children, term.explain(s, id))
+	}
+	return e
+}
+
+func (n opNode) explain(s *searcher, id ID) Explanation {
+	results, score := n.eval(s)
+	e := Explanation{Description: setOperationName(n.op), SetOperation: n.op}
+	e.Score, e.Match = explainResult(results, score, id)
+	for _, child := range n.children {
+		e.Children = append(e.Children, child.explain(s, id))
+	}
+	return e
+}
+
+func (n notNode) explain(s *searcher, id ID) Explanation {
+	child := n.child.explain(s, id)
+	return Explanation{Description: "NOT", Match: !child.Match, Children: []Explanation{child}}
+}
+
+func (n patternNode) explain(s *searcher, id ID) Explanation {
+	e := s.explainMerged(id, func() ([]Result, termScore) { return n.eval(s) })
+	e.Description, e.Field, e.Boost = n.String(), n.field, n.boost
+	return e
+}
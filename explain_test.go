@@ -0,0 +1,78 @@
+package minsearch
+
+import (
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	pairs := []Pair{
+		{ID: 1, Text: []byte("berlin mauer")},
+		{ID: 2, Text: []byte("berlin berliner")},
+		{ID: 3, Text: []byte("mauer"), Field: "title"},
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetBoost(2, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{"berlin^2 mauer", "berl* -mauer", "title:mauer OR berlin", "/berl.*/ maur"} {
+		for _, opts := range []SearchOptions{{}, {Syntax: true}, {Boolean: true}, {Syntax: true, SetOperation: Intersection},
+			{Syntax: true, MinShouldMatch: 2}, {Syntax: true, MaxDistance: 1}} {
+			results, err := f.SearchWithOptions([]byte(query), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scores := make(map[ID]Score)
+			for _, r := range results {
+				scores[r.ID] = r.Score
+			}
+			ids := []ID{1, 2, 3, 4}
+			explanations, err := f.ExplainResults([]byte(query), ids, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for idx, id := range ids {
+				e, err := f.ExplainWithOptions([]byte(query), id, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if score, ok := scores[id]; e.Match != ok || e.Score != score {
+					t.Errorf("ExplainWithOptions(%s, %d, %+v) = %v, %v; expected %v, %v\n%s",
+						query, id, opts, e.Score, e.Match, score, ok, e)
+				}
+				if explanations[idx].String() != e.String() {
+					t.Errorf("ExplainResults(%s, %+v)[%d] = %s; expected %s", query, opts, idx, explanations[idx], e)
+				}
+			}
+		}
+	}
+
+	e, err := f.Explain([]byte("berlin mauer"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Description != "union" || len(e.Children) != 2 || len(e.Children[0].Children) != 2 {
+		t.Fatalf("Explain(berlin mauer, 1) = %s; expected a union of 2 terms in 2 fields", e)
+	}
+	// the default scorer adds 1 + posting score / number of results
+	if berlin := e.Children[0].Children[0]; string(berlin.Segment) != "berlin" || !berlin.Match ||
+		berlin.DocFreq != 2 || berlin.Score != 1+berlin.PostingScore/2 {
+		t.Errorf("Explain(berlin mauer, 1) = %s; expected berlin with 2 results", e)
+	}
+	if title := e.Children[1].Children[1]; title.Field != "title" || title.Match || title.DocFreq != 1 {
+		t.Errorf("Explain(berlin mauer, 1) = %s; expected no match of title:mauer with 1 result", e)
+	}
+
+	e, err = f.Explain([]byte("berlin"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Boost != 2 || len(e.Children) != 1 || e.Score != 2*e.Children[0].Score {
+		t.Errorf("Explain(berlin, 2) = %s; expected the static boost 2", e)
+	}
+}
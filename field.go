@@ -0,0 +1,190 @@
+package minsearch
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/boltdb/bolt"
+)
+
+const dbStatsFields = `fields`
+
+// fieldSeparator separates the field name from the segment in the keys of named fields.
+// Normalized segments never contain it.
+const fieldSeparator = 0
+
+// ErrInvalidField is returned if the field of a Pair is not a valid field name.
+var ErrInvalidField = errors.New("minsearch: field names must only contain lower case letters, digits and '_'")
+
+// isFieldName reports whether the name can be used as the name of a field.
+// Field names can be written in queries like `title:berlin`,
+// so they consist of lower case ASCII letters, digits and '_'
+// and start with a letter.
+func isFieldName(name []byte) bool {
+	if len(name) == 0 || name[0] < 'a' || name[0] > 'z' {
+		return false
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldKey returns the key of the segment in the given field.
+// The keys of the default field are the segments themselves.
+func fieldKey(field string, segment []byte) []byte {
+	if len(field) == 0 {
+		return segment
+	}
+	key := make([]byte, 0, len(field)+1+len(segment))
+	key = append(key, field...)
+	key = append(key, fieldSeparator)
+	return append(key, segment...)
+}
+
+// fieldPrefix returns the common prefix of all keys of the given field.
+func fieldPrefix(field string) []byte {
+	return fieldKey(field, []byte{})
+}
+
+// inField reports whether the key belongs to the field with the given prefix.
+func inField(key, prefix []byte) bool {
+	return bytes.HasPrefix(key, prefix) && bytes.IndexByte(key[len(prefix):], fieldSeparator) < 0
+}
+
+// keySegment returns the segment of a key of any field.
+func keySegment(key []byte) []byte {
+	return key[bytes.IndexByte(key, fieldSeparator)+1:]
+}
+
+// keyField returns the field of a key; the default field has the empty name.
+func keyField(key []byte) string {
+	if idx := bytes.IndexByte(key, fieldSeparator); idx >= 0 {
+		return string(key[:idx])
+	}
+	return ""
+}
+
+// readFields returns the names of all named fields of the File in ascending order.
+func readFields(tx *bolt.Tx) []string {
+	var fields []string
+	for _, field := range decodeSegments(tx.Bucket([]byte{bucketStats}).Get([]byte(dbStatsFields))) {
+		fields = append(fields, string(field))
+	}
+	return fields
+}
+
+// addField stores the name of a named field, if it's not stored yet.
+func addField(tx *bolt.Tx, field string) error {
+	fields := readFields(tx)
+	names := make([][]byte, 0, len(fields)+1)
+	for _, name := range fields {
+		if name == field {
+			return nil
+		}
+		names = append(names, []byte(name))
+	}
+	names = append(names, []byte(field))
+	return tx.Bucket([]byte{bucketStats}).Put([]byte(dbStatsFields), encodeSegments(names))
+}
+
+// fieldString returns the query syntax restricting a term to the field.
+func fieldString(field string) string {
+	if len(field) == 0 {
+		return ""
+	}
+	return field + ":"
+}
+
+// withField returns the term or pattern restricted to the field.
+func withField(n node, field string) node {
+	switch f := n.(type) {
+	case termNode:
+		f.field = field
+		return f
+	case patternNode:
+		f.field = field
+		return f
+	}
+	return n
+}
+
+// fieldWeight is a field searched by terms without a field and its weight.
+type fieldWeight struct {
+	name   string
+	weight Score
+}
+
+// searchedFields returns the fields of the File that are searched
+// by terms without a field using the given weights.
+// Fields without a weight get weight 1; fields with a weight <= 0 are not searched.
+func searchedFields(fields []string, weights map[string]float32) []fieldWeight {
+	var searched []fieldWeight
+	for _, name := range append([]string{""}, fields...) {
+		weight, ok := weights[name]
+		if !ok {
+			weight = 1
+		} else if weight <= 0 {
+			continue
+		}
+		searched = append(searched, fieldWeight{name: name, weight: weight})
+	}
+	return searched
+}
+
+// expandFields returns the node where each term, pattern and NEAR/n without a field
+// is replaced by the union of its copies restricted to each of the fields
+// with their scores multiplied by the weights of the fields.
+// The union of each term is searched like a single segment (see opNode.fields).
+func expandFields(n node, fields []fieldWeight) node {
+	if len(fields) == 1 && len(fields[0].name) == 0 && fields[0].weight == 1 {
+		return n // only the default field, as if there were no fields
+	}
+	var expand func(field string) node
+	switch t := n.(type) {
+	case termNode:
+		if len(t.field) > 0 {
+			return n
+		}
+		expand = func(field string) node { return withField(t, field) }
+	case patternNode:
+		if len(t.field) > 0 {
+			return n
+		}
+		expand = func(field string) node { return withField(t, field) }
+	case nearNode:
+		for _, term := range t.terms {
+			if len(term.field) > 0 {
+				return n
+			}
+		}
+		expand = func(field string) node {
+			terms := make([]termNode, len(t.terms))
+			for idx, term := range t.terms {
+				term.field = field
+				terms[idx] = term
+			}
+			return nearNode{terms: terms, distances: t.distances}
+		}
+	case opNode:
+		children := make([]node, len(t.children))
+		for idx, child := range t.children {
+			children[idx] = expandFields(child, fields)
+		}
+		return opNode{op: t.op, children: children}
+	case notNode:
+		return notNode{child: expandFields(t.child, fields)}
+	default:
+		return n
+	}
+	children := make([]node, len(fields))
+	for idx, field := range fields {
+		children[idx] = withBoost(expand(field.name), field.weight)
+	}
+	if len(children) == 1 {
+		return children[0]
+	}
+	return opNode{op: Union, children: children, fields: true}
+}
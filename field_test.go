@@ -0,0 +1,75 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearchFields(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{Positions: true})
+	defer cleanup()
+
+	pairs := []Pair{
+		{ID: 1, Text: []byte("Berlin"), Field: "title"},
+		{ID: 1, Text: []byte("Die Hauptstadt mit der Mauer")},
+		{ID: 2, Text: []byte("Mauer"), Field: "title"},
+		{ID: 2, Text: []byte("Die Mauer in Berlin")},
+		{ID: 3, Text: []byte("Bern"), Field: "title"},
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.IndexPair(Pair{ID: 4, Text: []byte("x"), Field: "Title"}, 0); err != ErrInvalidField {
+		t.Errorf("IndexPair with invalid field returned %v; expected ErrInvalidField", err)
+	}
+
+	for _, test := range []struct {
+		query    string
+		opts     SearchOptions
+		expected []ID
+	}{
+		{"berlin", SearchOptions{}, []ID{1, 2}},
+		{"title:berlin", SearchOptions{}, []ID{1}},
+		{`title:"berlin"`, SearchOptions{}, []ID{1}},
+		{"title:ber*", SearchOptions{}, []ID{1, 3}},
+		{"ber*", SearchOptions{FieldWeights: map[string]float32{"title": 0}}, []ID{2}},
+		{"title:/b.*/", SearchOptions{}, []ID{1, 3}},
+		{"title:berln", SearchOptions{MaxDistance: 1}, []ID{1, 3}},
+		{"mauer", SearchOptions{FieldWeights: map[string]float32{"": 0}}, []ID{2}},
+		{`"mauer in berlin"`, SearchOptions{}, []ID{2}},
+		{"hauptstadt NEAR/3 mauer", SearchOptions{}, []ID{1}},
+	} {
+		test.opts.Syntax = true
+		results, err := f.SearchWithOptions([]byte(test.query), test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := sortedIDs(results); !equalIDs(ids, test.expected) {
+			t.Errorf("SearchWithOptions(%s, %+v) = %v; expected IDs %v", test.query, test.opts, results, test.expected)
+		}
+	}
+
+	// the title match of ID 1 outweighs the text match of ID 2
+	results, err := f.SearchWithOptions([]byte("berlin"), SearchOptions{FieldWeights: map[string]float32{"title": 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != 1 || results[0].Score <= 2*results[1].Score {
+		t.Errorf("SearchWithOptions(berlin, title^5) = %v; expected ID 1 far ahead", results)
+	}
+
+	completions, err := f.Complete([]byte("ber"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(completions) != "[berlin bern]" {
+		t.Errorf("Complete(ber) = %v; expected [berlin bern]", completions)
+	}
+	suggestions, err := f.Suggest([]byte("berln"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(suggestions) != "[berlin]" {
+		t.Errorf("Suggest(berln) = %v; expected [berlin]", suggestions)
+	}
+}
@@ -22,6 +22,11 @@ const sizeResult = sizeID + sizeScore
 const (
 	bucketStats byte = iota
 	bucketWords
+	bucketForward
+	bucketLengths
+	bucketPositions
+	bucketReversed
+	bucketBoosts
 )
 
 // File is the index file.
@@ -29,6 +34,44 @@ type File struct {
 	db       *bolt.DB
 	keyCount uint32
 	avgCount float32
+	scorer   Scorer
+	analyzer Analyzer
+}
+
+// Options are the options used to open a File.
+type Options struct {
+	// NoSync causes the database to skip fsync() calls after each commit.
+	// In the event of a system failure data can get lost,
+	// so setting it is unsafe but makes indexing much faster.
+	NoSync bool
+	// ForwardIndex creates the forward index which stores for each ID
+	// the segments it was indexed under. It makes Delete much faster
+	// and allows to use Segments, but increases the file size.
+	// The forward index can only be created together with the File;
+	// once created, it's maintained regardless of this option.
+	ForwardIndex bool
+	// Positions creates the positional index which stores the positions
+	// of the segments in the indexed texts. It's needed for exact phrase
+	// and proximity queries, but increases the file size a lot.
+	// Like the forward index it can only be created together with the File.
+	Positions bool
+	// ReversedKeys stores all segments reversed, which makes
+	// patterns like `*burg` much faster (see Search).
+	// Like the forward index it can only be created together with the File.
+	ReversedKeys bool
+	// Scorer calculates the scores while indexing and searching.
+	// Whether the File is indexed with BM25 is stored when the File is created:
+	// an existing File indexed with BM25 can't be opened or searched with another
+	// Scorer and vice versa (see ErrScorerMismatch).
+	// If Scorer is nil, BM25{K1: 1.2, B: 0.75} is used for a File indexed
+	// with BM25 and DefaultScorer otherwise.
+	Scorer Scorer
+	// Analyzer reduces the normalized segments of the indexed texts and queries,
+	// like to their stems. It's stored in the File when the File is created,
+	// so the queries are always analyzed like the indexed texts.
+	// An existing File is always opened with its stored Analyzer; opening it
+	// with another Analyzer than DefaultAnalyzer returns ErrAnalyzerMismatch.
+	Analyzer Analyzer
 }
 
 // Open opens the File or creates a new File if it doesn't exist.
@@ -36,23 +79,68 @@ type File struct {
 // calls after each commit. In the event of a system failure
 // data can get lost, so setting it is unsafe but makes indexing much faster.
 func Open(filename string, noSync bool) (*File, error) {
+	return OpenWithOptions(filename, Options{NoSync: noSync})
+}
+
+// OpenWithOptions opens the File using the given options
+// or creates a new File if it doesn't exist.
+func OpenWithOptions(filename string, options Options) (*File, error) {
 	var f = &File{}
 	var err error
 	f.db, err = bolt.Open(filename, 0600, &bolt.Options{Timeout: 1 * time.Second})
 	if err != nil {
 		return nil, err
 	}
-	f.db.NoSync = noSync
+	f.db.NoSync = options.NoSync
 	err = f.db.Update(func(tx *bolt.Tx) error {
-		_, e := tx.CreateBucketIfNotExists([]byte{bucketWords})
+		words, e := tx.CreateBucketIfNotExists([]byte{bucketWords})
+		if e != nil {
+			return e
+		}
+		k, _ := words.Cursor().First()
+		isEmpty := k == nil
+		for _, optional := range [...]struct {
+			enabled bool
+			bucket  byte
+			name    string
+		}{
+			{options.ForwardIndex, bucketForward, "forward index"},
+			{options.Positions, bucketPositions, "positional index"},
+			{options.ReversedKeys, bucketReversed, "reversed keys"},
+		} {
+			if !optional.enabled || tx.Bucket([]byte{optional.bucket}) != nil {
+				continue
+			}
+			if !isEmpty {
+				return fmt.Errorf("minsearch: %s can only be created for an empty File", optional.name)
+			}
+			_, e = tx.CreateBucket([]byte{optional.bucket})
+			if e != nil {
+				return e
+			}
+		}
+		_, e = tx.CreateBucketIfNotExists([]byte{bucketLengths})
+		if e != nil {
+			return e
+		}
+		_, e = tx.CreateBucketIfNotExists([]byte{bucketBoosts})
 		if e != nil {
 			return e
 		}
 		_, e = tx.CreateBucketIfNotExists([]byte{bucketStats})
+		if e != nil {
+			return e
+		}
+		f.scorer, e = openScorer(tx, options.Scorer, isEmpty)
+		if e != nil {
+			return e
+		}
+		f.analyzer, e = openAnalyzer(tx, options.Analyzer, isEmpty)
 		return e
 	})
 
 	if err != nil {
+		f.db.Close()
 		return nil, err
 	}
 
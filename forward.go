@@ -0,0 +1,89 @@
+package minsearch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"github.com/boltdb/bolt"
+)
+
+// ErrNoForwardIndex is returned if an operation needs the forward index
+// but the File was not created with Options.ForwardIndex.
+var ErrNoForwardIndex = errors.New("minsearch: File has no forward index")
+
+// Segments returns the normalized segments the given ID was indexed under
+// in ascending order. The File must have a forward index (see Options.ForwardIndex).
+// If maxIDs was used during indexing, the ID may already be displaced
+// from the results of some of the returned segments.
+// Segments of named fields (see Pair.Field) are returned as their keys,
+// which consist of the field name, a 0 byte and the segment.
+func (f *File) Segments(id ID) ([][]byte, error) {
+	var segments [][]byte
+	err := f.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte{bucketForward})
+		if bucket == nil {
+			return ErrNoForwardIndex
+		}
+		segments = decodeSegments(bucket.Get(idKey(id)))
+		for idx, segment := range segments {
+			segments[idx] = append([]byte(nil), segment...) // only valid during tx
+		}
+		return nil
+	})
+	return segments, err
+}
+
+// idKey returns the key used for the given ID.
+// The big endian encoding keeps the keys sorted by ID.
+func idKey(id ID) []byte {
+	var key [sizeID]byte
+	binary.BigEndian.PutUint32(key[:], id)
+	return key[:]
+}
+
+// addForward merges the given segments into the forward index entry of the ID.
+func addForward(bucket *bolt.Bucket, id ID, segments [][]byte) error {
+	if len(segments) == 0 {
+		return nil
+	}
+	key := idKey(id)
+	merged := append(decodeSegments(bucket.Get(key)), segments...)
+	return bucket.Put(key, encodeSegments(merged))
+}
+
+// encodeSegments sorts and deduplicates the segments
+// and encodes them each prefixed by its uvarint length.
+func encodeSegments(segments [][]byte) []byte {
+	sort.Slice(segments, func(i, j int) bool {
+		return bytes.Compare(segments[i], segments[j]) < 0
+	})
+	var data []byte
+	var lenBytes [binary.MaxVarintLen64]byte
+	for idx, segment := range segments {
+		if idx > 0 && bytes.Equal(segment, segments[idx-1]) {
+			continue
+		}
+		n := binary.PutUvarint(lenBytes[:], uint64(len(segment)))
+		data = append(data, lenBytes[:n]...)
+		data = append(data, segment...)
+	}
+	return data
+}
+
+// decodeSegments decodes data encoded by encodeSegments.
+// The returned segments share the memory of data.
+func decodeSegments(data []byte) [][]byte {
+	var segments [][]byte
+	for len(data) > 0 {
+		segmentLen, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < segmentLen {
+			break // corrupt data
+		}
+		end := n + int(segmentLen)
+		segments = append(segments, data[n:end:end])
+		data = data[end:]
+	}
+	return segments
+}
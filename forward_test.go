@@ -0,0 +1,37 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSegments(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{ForwardIndex: true})
+	defer cleanup()
+
+	if err := f.IndexPair(Pair{ID: 7, Text: []byte("Berlin Mauer")}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.IndexPair(Pair{ID: 7, Text: []byte("Mauer Museum")}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, err := f.Segments(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, segment := range segments {
+		got = append(got, string(segment))
+	}
+	if expected := "[berlin mauer museum]"; fmt.Sprint(got) != expected {
+		t.Errorf("Segments(7) = %v; expected %s", got, expected)
+	}
+
+	if err := f.Delete(7); err != nil {
+		t.Fatal(err)
+	}
+	if segments, _ := f.Segments(7); len(segments) != 0 {
+		t.Errorf("Segments(7) after Delete = %q; expected none", segments)
+	}
+}
@@ -1,6 +1,8 @@
 package minsearch
 
 import (
+	"context"
+
 	"github.com/boltdb/bolt"
 	"github.com/tim-st/go-uniseg"
 )
@@ -9,6 +11,13 @@ import (
 type Pair struct {
 	ID   ID
 	Text []byte
+	// Field is the name of the field the text is indexed in, like "title".
+	// Segments of different fields are indexed under different keys,
+	// so searches can weight the fields and restrict terms to a field
+	// (see Search and SearchOptions.FieldWeights).
+	// The empty name is the default field, which Files created before
+	// fields existed use. Other names must be valid field names (see ErrInvalidField).
+	Field string
 }
 
 // IndexPair indexes all relevant segments of the given Pair.
@@ -30,88 +39,192 @@ func (f *File) IndexPair(pair Pair, maxIDs int) error {
 // IndexBatch indexes all relevant segments for each Pair as a batch operation.
 // See IndexPair for more information.
 func (f *File) IndexBatch(pairs []Pair, maxIDs int) error {
+	return f.IndexBatchContext(context.Background(), pairs, maxIDs)
+}
+
+// IndexBatchContext works like IndexBatch but stops indexing if ctx is done
+// and returns ctx.Err() then. The context is checked between the pairs.
+// A stopped batch is rolled back completely, so none of its pairs is indexed.
+func (f *File) IndexBatchContext(ctx context.Context, pairs []Pair, maxIDs int) error {
+	return f.db.Update(func(tx *bolt.Tx) error {
+		return indexBatch(ctx, tx, pairs, maxIDs, f.scorer, f.analyzer)
+	})
+}
+
+// Reindex replaces everything previously indexed for the ID of each Pair
+// by the segments and scores of the Pair's text as a single transaction.
+// If multiple pairs have the same ID, all their texts are indexed for the ID.
+// See IndexPair for the meaning of maxIDs and Delete for the
+// performance implications without a forward index.
+func (f *File) Reindex(pairs []Pair, maxIDs int) error {
 	return f.db.Update(func(tx *bolt.Tx) error {
-		relevantSegments := make(map[string]Score)
-		bucket := tx.Bucket([]byte{bucketWords})
-		for _, pair := range pairs {
-			// idiom optimized by compiler since go 1.11
-			for k := range relevantSegments {
-				delete(relevantSegments, k)
+		ids := make([]ID, len(pairs))
+		for idx, pair := range pairs {
+			ids[idx] = pair.ID
+		}
+		if err := deleteIDs(tx, ids); err != nil {
+			return err
+		}
+		return indexBatch(context.Background(), tx, pairs, maxIDs, f.scorer, f.analyzer)
+	})
+}
+
+func indexBatch(ctx context.Context, tx *bolt.Tx, pairs []Pair, maxIDs int, scorer Scorer, analyzer Analyzer) error {
+	relevantSegments := make(map[string]int)
+	var indexedSegments [][]byte
+	bucket := tx.Bucket([]byte{bucketWords})
+	forward := tx.Bucket([]byte{bucketForward})
+	lengths := tx.Bucket([]byte{bucketLengths})
+	positions := tx.Bucket([]byte{bucketPositions})
+	reversed := tx.Bucket([]byte{bucketReversed})
+	segmentPositions := make(map[string][]uint32)
+	collections := make(map[string]collection)
+	var field string
+	for _, pair := range pairs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(pair.Field) > 0 && pair.Field != field {
+			if !isFieldName([]byte(pair.Field)) {
+				return ErrInvalidField
+			}
+			if err := addField(tx, pair.Field); err != nil {
+				return err
 			}
-			segments := uniseg.Segments(pair.Text)
-			for _, segment := range segments {
-				if norm := normalizeSegment(segment); len(norm) > 0 {
-					relevantSegments[string(norm)]++
+			field = pair.Field
+		}
+		// idiom optimized by compiler since go 1.11
+		for k := range relevantSegments {
+			delete(relevantSegments, k)
+		}
+		for k := range segmentPositions {
+			delete(segmentPositions, k)
+		}
+		segments := uniseg.Segments(pair.Text)
+		var position uint32
+		for _, segment := range segments {
+			if norm := normalizeSegment(segment); len(norm) > 0 {
+				key := string(fieldKey(pair.Field, analyzer.stem(norm)))
+				relevantSegments[key]++
+				if positions != nil {
+					segmentPositions[key] = append(segmentPositions[key], position)
 				}
+				position++
 			}
+		}
 
-			const idxNotFound = -1
-			segmentsLen := Score(len(segments))
-			for element, count := range relevantSegments {
-				oldResultsData := bucket.Get([]byte(element))
-				oldResults := asResults(oldResultsData)
-				score := 1 + (count / segmentsLen)
+		var offset uint32
+		if positions != nil && len(relevantSegments) > 0 {
+			offset = nextPosition(positions, pair.ID)
+			// the gap prevents phrases spanning multiple texts
+			if err := setNextPosition(positions, pair.ID, offset+position+1); err != nil {
+				return err
+			}
+		}
 
-				if maxIDs > 0 && len(oldResults) >= maxIDs && oldResults[len(oldResults)-1].Score > score {
-					continue
-				}
+		if len(relevantSegments) > 0 {
+			c, ok := collections[pair.Field]
+			if !ok {
+				c = readCollection(tx, pair.Field)
+			}
+			if err := updateLength(lengths, &c, pair.Field, pair.ID, position); err != nil {
+				return err
+			}
+			collections[pair.Field] = c
+		}
 
-				var oldResultIdx = idxNotFound
-				var newResultIdx = idxNotFound
-				for idx, r := range oldResults {
+		indexedSegments = indexedSegments[:0]
+		const idxNotFound = -1
+		for element, count := range relevantSegments {
+			oldResultsData := bucket.Get([]byte(element))
+			oldResults := asResults(oldResultsData)
+			score := scorer.IndexScore(count, len(segments))
 
-					if newResultIdx == idxNotFound && (score > r.Score ||
-						(score == r.Score && pair.ID < r.ID)) {
-						newResultIdx = idx
-					}
+			if maxIDs > 0 && len(oldResults) >= maxIDs && oldResults[len(oldResults)-1].Score > score {
+				continue
+			}
 
-					if r.ID == pair.ID {
-						oldResultIdx = idx
-						break
-					}
+			var oldResultIdx = idxNotFound
+			var newResultIdx = idxNotFound
+			for idx, r := range oldResults {
 
+				if newResultIdx == idxNotFound && (score > r.Score ||
+					(score == r.Score && pair.ID < r.ID)) {
+					newResultIdx = idx
 				}
 
-				if newResultIdx == idxNotFound {
-					newResultIdx = len(oldResults)
+				if r.ID == pair.ID {
+					oldResultIdx = idx
+					break
 				}
 
-				var newResultsData []byte
-				if oldResultIdx == idxNotFound {
-
-					newResultsData = make([]byte, len(oldResultsData)+sizeResult)
-					newResults := asResults(newResultsData)
+			}
 
-					copy(newResults, oldResults[:newResultIdx])
-					newResults[newResultIdx].ID = pair.ID
-					newResults[newResultIdx].Score = score
-					copy(newResults[newResultIdx+1:], oldResults[newResultIdx:])
+			if newResultIdx == idxNotFound {
+				newResultIdx = len(oldResults)
+			}
 
-					if maxIDs > 0 && len(newResults) > maxIDs {
-						newResultsData = newResultsData[:len(newResultsData)-sizeResult] // remove last result
-					}
+			var newResultsData []byte
+			if oldResultIdx == idxNotFound {
 
-				} else if prevScore := oldResults[oldResultIdx].Score; score > prevScore {
-					newResultsData = make([]byte, len(oldResultsData))
-					newResults := asResults(newResultsData)
+				newResultsData = make([]byte, len(oldResultsData)+sizeResult)
+				newResults := asResults(newResultsData)
 
-					copy(newResults, oldResults[:newResultIdx])
-					newResults[newResultIdx].ID = pair.ID
-					newResults[newResultIdx].Score = score
-					copy(newResults[newResultIdx+1:], oldResults[newResultIdx:oldResultIdx])
-					copy(newResults[oldResultIdx+1:], oldResults[oldResultIdx+1:])
+				copy(newResults, oldResults[:newResultIdx])
+				newResults[newResultIdx].ID = pair.ID
+				newResults[newResultIdx].Score = score
+				copy(newResults[newResultIdx+1:], oldResults[newResultIdx:])
 
+				if maxIDs > 0 && len(newResults) > maxIDs {
+					newResultsData = newResultsData[:len(newResultsData)-sizeResult] // remove last result
 				}
 
-				if len(newResultsData) > 0 {
-					if err := bucket.Put([]byte(element), newResultsData); err != nil {
+			} else if prevScore := oldResults[oldResultIdx].Score; score > prevScore {
+				newResultsData = make([]byte, len(oldResultsData))
+				newResults := asResults(newResultsData)
+
+				copy(newResults, oldResults[:newResultIdx])
+				newResults[newResultIdx].ID = pair.ID
+				newResults[newResultIdx].Score = score
+				copy(newResults[newResultIdx+1:], oldResults[newResultIdx:oldResultIdx])
+				copy(newResults[oldResultIdx+1:], oldResults[oldResultIdx+1:])
+
+			}
+
+			if len(newResultsData) > 0 {
+				if err := bucket.Put([]byte(element), newResultsData); err != nil {
+					return err
+				}
+				if reversed != nil && len(oldResultsData) == 0 {
+					if err := reversed.Put(reverseKey([]byte(element)), []byte{}); err != nil {
 						return err
 					}
 				}
+			}
+
+			if forward != nil {
+				indexedSegments = append(indexedSegments, []byte(element))
+			}
 
+			if positions != nil {
+				if err := addPositions(positions, pair.ID, []byte(element), offset, segmentPositions[element]); err != nil {
+					return err
+				}
 			}
 
 		}
-		return nil
-	})
+
+		if forward != nil {
+			if err := addForward(forward, pair.ID, indexedSegments); err != nil {
+				return err
+			}
+		}
+
+	}
+	for field, c := range collections {
+		if err := writeCollection(tx, field, c); err != nil {
+			return err
+		}
+	}
+	return nil
 }
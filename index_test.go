@@ -0,0 +1,139 @@
+package minsearch
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func openTestFile(t *testing.T, options Options) (*File, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "minsearch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options.NoSync = true
+	f, err := OpenWithOptions(filepath.Join(dir, "test.idx"), options)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return f, func() {
+		f.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func resultIDs(results []Result) []ID {
+	ids := make([]ID, len(results))
+	for idx, r := range results {
+		ids[idx] = r.ID
+	}
+	return ids
+}
+
+func sortedIDs(results []Result) []ID {
+	ids := resultIDs(results)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func equalIDs(a, b []ID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReindex(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("Berlin Mauer Mauer Mauer")},
+		{ID: 2, Text: []byte("Mauer Berlin")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Reindex([]Pair{{ID: 1, Text: []byte("Hamburg Mauer")}}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := f.Search([]byte("berlin"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := resultIDs(results); !equalIDs(ids, []ID{2}) {
+		t.Errorf("Search(berlin) after Reindex = %v; expected [2]", ids)
+	}
+
+	results, err = f.Search([]byte("mauer"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Score != results[1].Score {
+		t.Errorf("Search(mauer) after Reindex = %v; expected equal scores", results)
+	}
+}
+
+func TestContext(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := f.IndexBatchContext(ctx, []Pair{{ID: 1, Text: []byte("berlin")}}, 0); err != context.Canceled {
+		t.Errorf("IndexBatchContext returned %v; expected context.Canceled", err)
+	}
+	if results, err := f.Search([]byte("berlin"), Union, 0); err != nil || len(results) != 0 {
+		t.Errorf("canceled batch was indexed: %v, %v", results, err)
+	}
+
+	if err := f.IndexBatch([]Pair{{ID: 1, Text: []byte("berlin")}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.SearchContext(ctx, []byte("berlin"), Union, 0); err != context.Canceled {
+		t.Errorf("SearchContext returned %v; expected context.Canceled", err)
+	}
+	if results, err := f.SearchContext(context.Background(), []byte("berlin"), Union, 0); err != nil || len(results) != 1 {
+		t.Errorf("SearchContext = %v, %v; expected 1 result", results, err)
+	}
+
+	// the scans of all keys check the context every contextCheckKeys keys
+	var pairs []Pair
+	for id := ID(2); id < 2*contextCheckKeys; id++ {
+		pairs = append(pairs, Pair{ID: id, Text: []byte(fmt.Sprintf("berlin%d", id))})
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.SuggestContext(ctx, []byte("berlni"), 1); err != context.Canceled {
+		t.Errorf("SuggestContext returned %v; expected context.Canceled", err)
+	}
+	err := f.db.View(func(tx *bolt.Tx) error {
+		s := newSearcher(tx, f.scorer, 0)
+		s.maxDistance = 2
+		all, _ := s.lookupFuzzy("", []byte("berlin10"))
+		s.ctx = ctx
+		if results, _ := s.lookupFuzzy("", []byte("berlin10")); len(results) >= len(all) {
+			t.Errorf("lookupFuzzy with a canceled context found %d results; expected less than %d", len(results), len(all))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,113 @@
+package minsearch
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+
+	"github.com/boltdb/bolt"
+)
+
+// ResultIterator iterates over the results of a search in the order of Search.
+// It holds a read transaction of the File until it's closed.
+type ResultIterator struct {
+	tx *bolt.Tx
+
+	// results of a single term, which are read directly from the index
+	results []Result
+	score   termScore
+	pos     int
+
+	// result set of all other queries, which is only ordered on demand
+	heap bestHeap
+}
+
+// SearchIter works like Search but returns an iterator over the results.
+// If the query consists of a single segment, the File has no named fields (see Pair.Field),
+// the Scorer is a MonotoneScorer and no static boosts are stored (see SetBoosts),
+// the results are read directly from the index without collecting a result set,
+// so maxResults is not needed. Otherwise the result set is collected like in Search,
+// but only the results returned by Next are ordered.
+// The iterator holds a read transaction until Close is called, which must always be done.
+// Writing to the File may block until all iterators are closed,
+// so an iterator must not be kept open longer than necessary
+// and must be closed before writing to the File in the same goroutine.
+func (f *File) SearchIter(query []byte, setOp SetOperation, maxResults int) (*ResultIterator, error) {
+	tx, err := f.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	it := &ResultIterator{tx: tx}
+	s, terms, setOp := f.prepareSearch(tx, literalTerms(query), SearchOptions{SetOperation: setOp, MaxResults: maxResults})
+	if len(terms) == 1 && isMonotone(s.scorer) && s.boosts == nil {
+		results, score := terms[0].eval(s)
+		if sort.SliceIsSorted(results, func(i, j int) bool {
+			return isBetter(results[i], results[j])
+		}) {
+			it.results, it.score = results, score
+			return it, nil
+		}
+		// merged results, like those of a segment searched in several fields, are unordered
+		qr := make(map[ID]Score, len(results))
+		union(results, qr, maxResults, score)
+		it.heap = bestHeap(resultsOf(qr))
+	} else {
+		qr, _, err := s.searchSet(context.Background(), terms, setOp)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		it.heap = bestHeap(resultsOf(qr))
+	}
+	heap.Init(&it.heap)
+	return it, nil
+}
+
+// Next returns the next result.
+// If there are no more results or the iterator is closed, ok is false.
+func (it *ResultIterator) Next() (r Result, ok bool) {
+	if it.tx == nil {
+		return Result{}, false
+	}
+	if it.results != nil {
+		if it.pos >= len(it.results) {
+			return Result{}, false
+		}
+		r = it.results[it.pos]
+		it.pos++
+		return Result{ID: r.ID, Score: it.score(r)}, true
+	}
+	if len(it.heap) == 0 {
+		return Result{}, false
+	}
+	return heap.Pop(&it.heap).(Result), true
+}
+
+// Close ends the read transaction of the iterator.
+// Calling Close more than once has no effect.
+func (it *ResultIterator) Close() error {
+	if it.tx == nil {
+		return nil
+	}
+	err := it.tx.Rollback()
+	it.tx, it.results, it.heap = nil, nil, nil
+	return err
+}
+
+// bestHeap is a heap of results with the best result on top.
+type bestHeap []Result
+
+func (h bestHeap) Len() int           { return len(h) }
+func (h bestHeap) Less(i, j int) bool { return isBetter(h[i], h[j]) }
+func (h bestHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *bestHeap) Push(x interface{}) {
+	*h = append(*h, x.(Result))
+}
+
+func (h *bestHeap) Pop() interface{} {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
@@ -0,0 +1,62 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearchIter(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	var pairs []Pair
+	for id := ID(1); id <= 50; id++ {
+		text := []byte("berlin")
+		for i := ID(0); i < id%7; i++ {
+			text = append(text, " mauer"...)
+		}
+		pairs = append(pairs, Pair{ID: id, Text: text})
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// the second round searches the segments in the title field, too
+	for round := 0; round < 2; round++ {
+		if round == 1 {
+			if err := f.IndexPair(Pair{ID: 51, Text: []byte("Mauer"), Field: "title"}, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		testSearchIter(t, f, round)
+	}
+}
+
+func testSearchIter(t *testing.T, f *File, round int) {
+	for _, query := range []string{"mauer", "berlin mauer", "mau*"} {
+		all, err := f.Search([]byte(query), Union, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		it, err := f.SearchIter([]byte(query), Union, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var results []Result
+		for r, ok := it.Next(); ok; r, ok = it.Next() {
+			results = append(results, r)
+		}
+		if err := it.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(results) != fmt.Sprint(all) {
+			t.Errorf("round %d: SearchIter(%s) = %v; expected %v", round, query, results, all)
+		}
+		if ids := sortedIDs(results); round == 1 && query != "mau*" && ids[len(ids)-1] != 51 {
+			t.Errorf("SearchIter(%s) = %v; expected ID 51 of the title field", query, results)
+		}
+		if _, ok := it.Next(); ok {
+			t.Errorf("Next after Close returned a result")
+		}
+	}
+}
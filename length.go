@@ -0,0 +1,100 @@
+package minsearch
+
+import (
+	"encoding/binary"
+
+	"github.com/boltdb/bolt"
+)
+
+const (
+	dbStatsDocCount  = `docCount`
+	dbStatsDocLength = `docLength`
+)
+
+// collection holds the statistics of the texts of all documents indexed in a field.
+type collection struct {
+	docCount  uint32
+	docLength uint64 // sum of all document lengths
+}
+
+func (c collection) avgLength() float32 {
+	if c.docCount == 0 {
+		return 0
+	}
+	return float32(float64(c.docLength) / float64(c.docCount))
+}
+
+// collectionKey returns the key of a statistic of the field in the statistics.
+// The keys of the default field are the names of the statistics.
+func collectionKey(field, name string) []byte {
+	return fieldKey(field, []byte(name))
+}
+
+func readCollection(tx *bolt.Tx, field string) collection {
+	bucket := tx.Bucket([]byte{bucketStats})
+	var c collection
+	if data := bucket.Get(collectionKey(field, dbStatsDocCount)); len(data) == 4 {
+		c.docCount = binary.LittleEndian.Uint32(data)
+	}
+	if data := bucket.Get(collectionKey(field, dbStatsDocLength)); len(data) == 8 {
+		c.docLength = binary.LittleEndian.Uint64(data)
+	}
+	return c
+}
+
+func writeCollection(tx *bolt.Tx, field string, c collection) error {
+	bucket := tx.Bucket([]byte{bucketStats})
+	var docCountBytes [4]byte
+	binary.LittleEndian.PutUint32(docCountBytes[:], c.docCount)
+	if err := bucket.Put(collectionKey(field, dbStatsDocCount), docCountBytes[:]); err != nil {
+		return err
+	}
+	var docLengthBytes [8]byte
+	binary.LittleEndian.PutUint64(docLengthBytes[:], c.docLength)
+	return bucket.Put(collectionKey(field, dbStatsDocLength), docLengthBytes[:])
+}
+
+// lengthKey returns the key of the length of the ID's text in the field.
+// The keys of the default field are the keys of the IDs.
+func lengthKey(field string, id ID) []byte {
+	return fieldKey(field, idKey(id))
+}
+
+// docLength returns the stored length of the text of the document with the given ID
+// in the given field. If the length is unknown, 0 is returned.
+func docLength(bucket *bolt.Bucket, field string, id ID) uint32 {
+	data := bucket.Get(lengthKey(field, id))
+	if len(data) != 4 {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(data)
+}
+
+// updateLength stores the number of relevant segments of an indexed text of the given ID in the field.
+// Each field has its own lengths, so a title doesn't get the length of the full text.
+// If multiple texts are indexed for the same ID in the same field,
+// the length of the longest text is used.
+func updateLength(bucket *bolt.Bucket, c *collection, field string, id ID, length uint32) error {
+	key := lengthKey(field, id)
+	oldLength := docLength(bucket, field, id)
+	if bucket.Get(key) == nil {
+		c.docCount++
+	} else if length <= oldLength {
+		return nil
+	}
+	c.docLength += uint64(length) - uint64(oldLength)
+	var lengthBytes [4]byte
+	binary.LittleEndian.PutUint32(lengthBytes[:], length)
+	return bucket.Put(key, lengthBytes[:])
+}
+
+// deleteLength removes the stored length of the given ID in the field.
+func deleteLength(bucket *bolt.Bucket, c *collection, field string, id ID) error {
+	key := lengthKey(field, id)
+	if bucket.Get(key) == nil {
+		return nil
+	}
+	c.docCount--
+	c.docLength -= uint64(docLength(bucket, field, id))
+	return bucket.Delete(key)
+}
@@ -0,0 +1,62 @@
+package minsearch
+
+// minMatch returns the number of the given number of terms that each result
+// must match according to the minimum-should-match options, or 0 if none is set.
+// The larger of MinShouldMatch and the rounded down percentage is used,
+// but at least 1 and at most the number of terms.
+func minMatch(opts SearchOptions, terms int) int {
+	if opts.MinShouldMatch <= 0 && opts.MinShouldMatchPercent <= 0 {
+		return 0
+	}
+	n := opts.MinShouldMatch
+	if percent := terms * opts.MinShouldMatchPercent / 100; percent > n {
+		n = percent
+	}
+	if n > terms {
+		n = terms
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// atLeast adds the results that match at least minMatch of the posting lists to the result set.
+// The score of a result is the number of matched lists plus the scores it gets from them.
+// The matched lists of all IDs are counted first, so only results that match enough lists
+// take the places limited by maxResults. It returns an upper bound of the scores of the
+// results skipped because of maxResults, or 0 if no result was skipped.
+func atLeast(lists []postingList, qr map[ID]Score, maxResults int, minMatch int) Score {
+	matches := make(map[ID]int)
+	for _, list := range lists {
+		for _, r := range list.results {
+			matches[r.ID]++
+		}
+	}
+	var bound Score
+	truncated := false
+	for _, list := range lists {
+		var skipped Score // highest score of a result that is not added
+		for _, r := range list.results {
+			if matches[r.ID] < minMatch {
+				continue
+			}
+			if _, exists := qr[r.ID]; exists || maxResults < 1 || len(qr) < maxResults {
+				qr[r.ID] += list.score(r)
+				continue
+			}
+			truncated = true
+			if current := list.score(r); current > skipped {
+				skipped = current
+			}
+		}
+		bound += skipped
+	}
+	for id := range qr {
+		qr[id] += Score(matches[id])
+	}
+	if !truncated {
+		return 0
+	}
+	return bound + Score(len(lists))
+}
@@ -0,0 +1,96 @@
+package minsearch
+
+import (
+	"testing"
+)
+
+func TestSearchMinShouldMatch(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	pairs := []Pair{
+		{ID: 1, Text: []byte("berlin mauer museum")},
+		{ID: 2, Text: []byte("berlin mauer")},
+		{ID: 3, Text: []byte("berlin museum")},
+		{ID: 4, Text: []byte("berlin")},
+		{ID: 5, Text: []byte("mauer museum checkpoint")},
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		opts     SearchOptions
+		expected []ID
+	}{
+		{SearchOptions{MinShouldMatch: 1}, []ID{1, 2, 3, 4, 5}},
+		{SearchOptions{MinShouldMatch: 2}, []ID{1, 2, 3, 5}},
+		{SearchOptions{MinShouldMatch: 3}, []ID{1}},
+		{SearchOptions{MinShouldMatch: 5}, []ID{1}},
+		{SearchOptions{MinShouldMatchPercent: 50}, []ID{1, 2, 3, 4, 5}},
+		{SearchOptions{MinShouldMatchPercent: 67}, []ID{1, 2, 3, 5}},
+	} {
+		results, err := f.SearchWithOptions([]byte("berlin mauer museum"), test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := sortedIDs(results); !equalIDs(ids, test.expected) {
+			t.Errorf("SearchWithOptions(%+v) = %v; expected IDs %v", test.opts, results, test.expected)
+		}
+	}
+
+	// results matching more segments are ranked higher
+	results, err := f.SearchWithOptions([]byte("berlin mauer museum"), SearchOptions{MinShouldMatch: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].ID != 1 || results[len(results)-1].ID != 4 {
+		t.Errorf("SearchWithOptions(MinShouldMatch: 1) = %v; expected ID 1 first and ID 4 last", results)
+	}
+}
+
+func TestSearchMinShouldMatchMaxResults(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	pairs := []Pair{
+		{ID: 1, Text: []byte("mauer mauer mauer")},
+		{ID: 2, Text: []byte("berlin mauer")},
+		{ID: 3, Text: []byte("berlin mauer")},
+		{ID: 4, Text: []byte("berlin")},
+		{ID: 5, Text: []byte("berlin")},
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// ID 1 is the best result of mauer, but doesn't match enough segments to take a place
+	opts := SearchOptions{MinShouldMatch: 2, MaxResults: 2}
+	results, err := f.SearchWithOptions([]byte("berlin mauer"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := sortedIDs(results); !equalIDs(ids, []ID{2, 3}) {
+		t.Errorf("SearchWithOptions(%+v) = %v; expected IDs [2 3]", opts, results)
+	}
+
+	// a segment matches once for all fields
+	pairs = []Pair{{ID: 6, Text: []byte("mauer")}, {ID: 6, Text: []byte("berlin"), Field: "title"}}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+	opts = SearchOptions{MinShouldMatch: 2, MaxResults: 3}
+	results, err = f.SearchWithOptions([]byte("berlin mauer"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := sortedIDs(results); !equalIDs(ids, []ID{2, 3, 6}) {
+		t.Errorf("SearchWithOptions(%+v) = %v; expected IDs [2 3 6]", opts, results)
+	}
+
+	for _, opts := range []SearchOptions{{Boolean: true, MinShouldMatch: 1}, {Boolean: true, MinShouldMatchPercent: 50}} {
+		if _, err := f.SearchWithOptions([]byte("berlin mauer"), opts); err != ErrConflictingOptions {
+			t.Errorf("SearchWithOptions(%+v) returned %v; expected ErrConflictingOptions", opts, err)
+		}
+	}
+}
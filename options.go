@@ -0,0 +1,197 @@
+package minsearch
+
+import (
+	"context"
+	"errors"
+
+	"github.com/boltdb/bolt"
+)
+
+// ErrConflictingOptions is returned if SearchOptions.Boolean is combined
+// with MinShouldMatch or MinShouldMatchPercent.
+var ErrConflictingOptions = errors.New("minsearch: MinShouldMatch can't be used with Boolean")
+
+// SearchOptions are the options of SearchWithOptions.
+// The zero value searches like Search with Union and maxResults <= 0.
+type SearchOptions struct {
+	// SetOperation combines the results of the relevant segments of the query.
+	SetOperation SetOperation
+	// MinShouldMatch is the minimum number of relevant segments of the query
+	// that each result of a Union must match. Results are scored by the number
+	// of matched segments plus the scores they get from the matched segments.
+	MinShouldMatch int
+	// MinShouldMatchPercent works like MinShouldMatch, but the minimum number
+	// is the given percentage of the relevant segments of the query rounded down.
+	// If both are set, the larger minimum number is used; it's always at least 1
+	// and at most the number of relevant segments.
+	MinShouldMatchPercent int
+	// Syntax parses the query using the following syntax instead of searching
+	// its segments literally like Search.
+	// Text in double quotes is searched as a phrase, which counts as a single segment of the query.
+	// Two segments or phrases combined by NEAR/n, like `berlin NEAR/3 mauer`,
+	// must occur with at most n-1 other relevant segments in between.
+	// Phrases and NEAR/n need the positional index (see Options.Positions);
+	// without it, they only require all their segments to match.
+	// A word ending with '*', like `berl*`, matches all segments starting with
+	// the last segment of the word. Other words containing the wildcards '*'
+	// (any number of runes) or '?' (a single rune), like `*burg` or `m??er`,
+	// and regular expressions between slashes, like `/m[ae]{1,2}ier/`, are matched
+	// against whole normalized segments; '?' at the end of a word is punctuation.
+	// Each prefix or pattern only uses the 1000 matching segments with the most results.
+	// Without a literal prefix all segments must be compared; patterns with a literal suffix
+	// are still fast, if the File stores reversed keys (see Options.ReversedKeys).
+	// Prefixes and patterns match the segments reduced by the Analyzer of the File.
+	// A word, phrase, pattern or regular expression followed by '^' and a positive number,
+	// like `berlin^3`, multiplies the scores of its segments by the number (see SearchWeighted).
+	// Terms are searched in all fields of the File (see Pair.Field) unless they start
+	// with a field name and ':', like `title:berlin` or `title:"new york"`.
+	Syntax bool
+	// Boolean parses the query as a boolean expression like SearchQuery,
+	// which ignores SetOperation. It implies Syntax and can't be combined
+	// with MinShouldMatch or MinShouldMatchPercent (see ErrConflictingOptions).
+	Boolean bool
+	// MaxResults limits the temporary results during the search (see Search).
+	// If MaxResults <= 0 the memory is not limited.
+	MaxResults int
+	// MaxDistance also finds indexed segments that differ from a segment
+	// of the query by an edit distance of at most MaxDistance (see SearchFuzzy).
+	// MaxDistance is capped at 2.
+	MaxDistance int
+	// FieldWeights multiply the scores of the segments found in the named fields
+	// (see Pair.Field); the empty name is the default field.
+	// Terms without a field are searched in all fields of the File and
+	// fields without a weight get weight 1. Fields with a weight <= 0
+	// are only searched by terms restricted to them, like `title:berlin`.
+	FieldWeights map[string]float32
+	// BoostFunc combines the score of each result with the static boost of its ID
+	// (see SetBoosts). If BoostFunc is nil, MultiplyBoost is used.
+	BoostFunc BoostFunc
+	// Scorer calculates the scores of the results.
+	// If Scorer is nil, the Scorer of the File is used.
+	// BM25 can only be used for a File indexed with BM25 and the other Scorers
+	// only for the other Files (see ErrScorerMismatch).
+	Scorer Scorer
+	// Offset is the number of results that are skipped.
+	Offset int
+	// Limit is the maximum number of returned results.
+	// If Limit <= 0 all results are returned.
+	Limit int
+	// After skips all results up to and including the result the Cursor was created from.
+	// Offset is applied to the remaining results.
+	After Cursor
+}
+
+// SearchWithOptions searches the query in the index file using the given options
+// and returns a result set ordered by score. See SearchOptions.Syntax for the query syntax.
+// If Limit > 0 only the best Offset+Limit results of the collected result set
+// are selected by a heap and sorted. Union searches without Offset,
+// After, MaxResults, MinShouldMatch and static boosts and with a MonotoneScorer
+// also stop reading the results of the query's segments as soon as no other result can reach
+// the best results.
+func (f *File) SearchWithOptions(query []byte, opts SearchOptions) ([]Result, error) {
+	results, _, err := f.searchWithOptions(context.Background(), query, opts)
+	return results, err
+}
+
+func (f *File) searchWithOptions(ctx context.Context, query []byte, opts SearchOptions) (results []Result, next Cursor, err error) {
+	return f.searchTerms(ctx, queryTerms(query, opts), opts)
+}
+
+// queryTerms returns the terms of the query parsed as selected by the options;
+// a boolean query is parsed into a single term.
+func queryTerms(query []byte, opts SearchOptions) []node {
+	switch {
+	case opts.Boolean:
+		if n := parseQuery(query); n != nil {
+			return []node{n}
+		}
+		return nil
+	case opts.Syntax:
+		return parseTerms(query)
+	}
+	return literalTerms(query)
+}
+
+// checkOptions returns an error if the options conflict
+// or don't fit the File.
+func (f *File) checkOptions(opts SearchOptions) error {
+	if opts.Boolean && (opts.MinShouldMatch > 0 || opts.MinShouldMatchPercent > 0) {
+		return ErrConflictingOptions
+	}
+	if opts.Scorer != nil && scoresOf(opts.Scorer) != scoresOf(f.scorer) {
+		return ErrScorerMismatch
+	}
+	return nil
+}
+
+// searchTerms searches the parsed terms of a query using the given options.
+func (f *File) searchTerms(ctx context.Context, terms []node, opts SearchOptions) (results []Result, next Cursor, err error) {
+	if err := f.checkOptions(opts); err != nil {
+		return nil, "", err
+	}
+	var after *Result
+	if len(opts.After) > 0 {
+		r, err := opts.After.result()
+		if err != nil {
+			return nil, "", err
+		}
+		after = &r
+	}
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+	err = f.db.View(func(tx *bolt.Tx) error {
+		s, terms, setOp := f.prepareSearch(tx, terms, opts)
+		s.ctx = ctx
+		if e := ctx.Err(); e != nil {
+			return e
+		}
+		if setOp == Union && s.minMatch == 0 && s.boosts == nil && opts.Limit > 0 && opts.Offset == 0 && after == nil &&
+			opts.MaxResults <= 0 && isMonotone(s.scorer) {
+			// one more result tells whether more results follow
+			lists := postingLists(s, terms)
+			if e := ctx.Err(); e != nil {
+				return e
+			}
+			results = unionTopK(lists, opts.Limit+1)
+			if len(results) > opts.Limit {
+				results = results[:opts.Limit]
+				next = NewCursor(results[len(results)-1])
+			}
+			return nil
+		}
+		qr, _, e := s.searchSet(ctx, terms, setOp)
+		if e != nil {
+			return e
+		}
+		results, next = page(qr, after, opts.Offset, opts.Limit)
+		return nil
+	})
+	return results, next, err
+}
+
+// prepareSearch returns the searcher, the terms expanded to the searched fields
+// and the set operation of a search of the parsed terms using the given options.
+func (f *File) prepareSearch(tx *bolt.Tx, terms []node, opts SearchOptions) (*searcher, []node, SetOperation) {
+	scorer := opts.Scorer
+	if scorer == nil {
+		scorer = f.scorer
+	}
+	s := newSearcher(tx, scorer, opts.MaxResults)
+	s.maxDistance = opts.MaxDistance
+	if opts.BoostFunc != nil {
+		s.boost = opts.BoostFunc
+	}
+	fields := searchedFields(readFields(tx), opts.FieldWeights)
+	expanded := make([]node, len(terms))
+	for idx, term := range f.analyzer.stemTerms(terms) {
+		expanded[idx] = expandFields(term, fields)
+	}
+	setOp := opts.SetOperation
+	if opts.Boolean {
+		setOp = Union
+	} else if setOp == Union {
+		s.minMatch = minMatch(opts, len(expanded))
+	}
+	return s, expanded, setOp
+}
@@ -0,0 +1,66 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearchWithOptions(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	var pairs []Pair
+	for id := ID(1); id <= 50; id++ {
+		text := []byte("berlin")
+		for i := ID(0); i < id%7; i++ {
+			text = append(text, " mauer"...)
+		}
+		pairs = append(pairs, Pair{ID: id, Text: text})
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := f.Search([]byte("berlin mauer"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, next, err := f.SearchPage([]byte("berlin mauer"), SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(results) != fmt.Sprint(all[:10]) || next != NewCursor(all[9]) {
+		t.Errorf("SearchPage(Limit: 10) = %v, %q; expected %v, %q", results, next, all[:10], NewCursor(all[9]))
+	}
+
+	for _, test := range []struct {
+		query    string
+		opts     SearchOptions
+		expected func() ([]Result, error)
+	}{
+		{"berlin mauer", SearchOptions{SetOperation: Intersection, MaxResults: 20}, func() ([]Result, error) {
+			return f.Search([]byte("berlin mauer"), Intersection, 20)
+		}},
+		{"berlin maurr", SearchOptions{MaxDistance: 1}, func() ([]Result, error) {
+			return f.SearchFuzzy([]byte("berlin maurr"), Union, 1, 0)
+		}},
+		{"berlin mauer", SearchOptions{Limit: 5}, func() ([]Result, error) {
+			return f.SearchTopK([]byte("berlin mauer"), Union, 5)
+		}},
+		{"berlin -mauer", SearchOptions{Boolean: true}, func() ([]Result, error) {
+			return f.SearchQuery([]byte("berlin -mauer"), 0)
+		}},
+	} {
+		expected, err := test.expected()
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := f.SearchWithOptions([]byte(test.query), test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) == 0 || fmt.Sprint(results) != fmt.Sprint(expected) {
+			t.Errorf("SearchWithOptions(%s, %+v) = %v; expected %v", test.query, test.opts, results, expected)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package minsearch
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrInvalidCursor is returned if a Cursor was not created by NewCursor.
+var ErrInvalidCursor = errors.New("minsearch: invalid cursor")
+
+// Cursor is an opaque token that marks a position in an ordered result set.
+// The empty Cursor marks the start of the result set.
+type Cursor string
+
+// NewCursor returns the Cursor that marks the position after the given result.
+func NewCursor(r Result) Cursor {
+	var data [sizeResult]byte
+	binary.BigEndian.PutUint32(data[:sizeScore], math.Float32bits(r.Score))
+	binary.BigEndian.PutUint32(data[sizeScore:], r.ID)
+	return Cursor(base64.RawURLEncoding.EncodeToString(data[:]))
+}
+
+// result returns the result the Cursor was created from.
+func (c Cursor) result() (Result, error) {
+	data, err := base64.RawURLEncoding.DecodeString(string(c))
+	if err != nil || len(data) != sizeResult {
+		return Result{}, ErrInvalidCursor
+	}
+	return Result{
+		ID:    binary.BigEndian.Uint32(data[sizeScore:]),
+		Score: math.Float32frombits(binary.BigEndian.Uint32(data[:sizeScore])),
+	}, nil
+}
+
+// SearchPage works like SearchWithOptions but also returns the Cursor to continue with
+// (see SearchOptions.After), if more results follow the returned results; otherwise next is empty.
+// Results are ordered by descending score and ascending ID, so a Cursor
+// stays valid as long as the index, the query and the options are the same.
+func (f *File) SearchPage(query []byte, opts SearchOptions) (results []Result, next Cursor, err error) {
+	return f.searchWithOptions(context.Background(), query, opts)
+}
+
+// page returns the results of the result set that follow after (if not nil) and offset,
+// up to limit results, and the Cursor of the last returned result, if more results follow.
+func page(qr map[ID]Score, after *Result, offset, limit int) (results []Result, next Cursor) {
+	if after != nil {
+		for id, score := range qr {
+			if !isBetter(*after, Result{ID: id, Score: score}) {
+				delete(qr, id)
+			}
+		}
+	}
+	if limit > 0 {
+		results = topResults(qr, offset+limit)
+	} else {
+		results = resultsOf(qr)
+		sortResults(results)
+	}
+	if offset >= len(results) {
+		return nil, ""
+	}
+	results = results[offset:]
+	if limit > 0 && len(qr) > offset+limit {
+		next = NewCursor(results[len(results)-1])
+	}
+	return results, next
+}
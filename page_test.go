@@ -0,0 +1,59 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearchPage(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	var pairs []Pair
+	for id := ID(1); id <= 50; id++ {
+		text := []byte("berlin")
+		for i := ID(0); i < id%7; i++ {
+			text = append(text, " mauer"...)
+		}
+		pairs = append(pairs, Pair{ID: id, Text: text})
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := f.Search([]byte("berlin mauer"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, _, err := f.SearchPage([]byte("berlin mauer"), SearchOptions{Offset: 15, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(results) != fmt.Sprint(all[15:25]) {
+		t.Errorf("SearchPage(Offset: 15, Limit: 10) = %v; expected %v", results, all[15:25])
+	}
+
+	var paged []Result
+	var cursor Cursor
+	for pages := 1; ; pages++ {
+		results, next, err := f.SearchPage([]byte("berlin mauer"), SearchOptions{Limit: 7, After: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		paged = append(paged, results...)
+		if len(next) == 0 {
+			if pages != 8 {
+				t.Errorf("paging took %d pages; expected 8", pages)
+			}
+			break
+		}
+		cursor = next
+	}
+	if fmt.Sprint(paged) != fmt.Sprint(all) {
+		t.Errorf("paged results = %v; expected %v", paged, all)
+	}
+
+	if _, _, err := f.SearchPage([]byte("berlin"), SearchOptions{After: "invalid"}); err != ErrInvalidCursor {
+		t.Errorf("SearchPage with invalid cursor returned %v; expected ErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,182 @@
+package minsearch
+
+import (
+	"bytes"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// patternNode matches all results of the keys matching a pattern.
+type patternNode struct {
+	text   string
+	re     *regexp.Regexp // matches the whole key
+	prefix []byte         // literal prefix of all matching keys
+	suffix []byte         // literal suffix of a wildcard pattern
+	boost  Score          // multiplies the scores; 0 means no boost
+	field  string         // the field of the matching keys
+}
+
+// newWildcardNode returns the node of a pattern where '*' matches
+// any number of runes and '?' matches a single rune.
+// The literal parts of the pattern are normalized.
+// It returns nil if the pattern has no literal parts.
+func newWildcardNode(pattern []byte) node {
+	var expr, text []byte
+	var literal, suffix []byte
+	hasLiteral := false
+	flush := func() {
+		suffix = normalize(literal)
+		hasLiteral = hasLiteral || len(suffix) > 0
+		expr = append(expr, regexp.QuoteMeta(string(suffix))...)
+		text = append(text, suffix...)
+		literal = literal[:0]
+	}
+	for len(pattern) > 0 {
+		r, width := utf8.DecodeRune(pattern)
+		switch r {
+		case '*', '?':
+			flush()
+			if r == '*' {
+				expr = append(expr, ".*"...)
+			} else {
+				expr = append(expr, '.')
+			}
+			text = append(text, byte(r))
+		default:
+			literal = append(literal, pattern[:width]...)
+		}
+		pattern = pattern[width:]
+	}
+	flush()
+	if !hasLiteral {
+		return nil
+	}
+	re, err := regexp.Compile("^(?:" + string(expr) + ")$")
+	if err != nil {
+		return nil
+	}
+	return patternNode{text: string(text), re: re, prefix: literalPrefix(string(expr)), suffix: suffix}
+}
+
+// foldPattern returns the wildcard pattern with its literal parts folded
+// by the Analyzer (see Analyzer.fold). Regular expressions are kept unchanged.
+func (a Analyzer) foldPattern(n patternNode) node {
+	if strings.HasPrefix(n.text, "/") {
+		return n
+	}
+	var pattern, literal []byte
+	for _, c := range []byte(n.text) {
+		if c == '*' || c == '?' {
+			pattern = append(append(pattern, a.fold(literal)...), c)
+			literal = literal[:0]
+		} else {
+			literal = append(literal, c)
+		}
+	}
+	pattern = append(pattern, a.fold(literal)...)
+	folded, ok := newWildcardNode(pattern).(patternNode)
+	if !ok {
+		return n
+	}
+	folded.boost, folded.field = n.boost, n.field
+	return folded
+}
+
+// newRegexpNode returns the node of a regular expression
+// that must match a whole normalized key.
+// It returns nil if the expression is invalid.
+func newRegexpNode(expr []byte) node {
+	re, err := regexp.Compile("^(?:" + string(expr) + ")$")
+	if err != nil {
+		return nil
+	}
+	return patternNode{text: "/" + string(expr) + "/", re: re, prefix: literalPrefix(string(expr))}
+}
+
+// literalPrefix returns the literal prefix of all strings matched by the
+// valid regular expression expr. Unlike Regexp.LiteralPrefix it doesn't depend
+// on the expression being one-pass, which the anchored expression of `st*sse` isn't.
+func literalPrefix(expr string) []byte {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return nil
+	}
+	prefix, _ := prog.Prefix()
+	return []byte(prefix)
+}
+
+func (n patternNode) eval(s *searcher) ([]Result, termScore) {
+	results, score := s.lookupPattern(n.field, n.re, n.prefix, n.suffix)
+	return results, boosted(score, n.boost)
+}
+
+func (n patternNode) String() string {
+	return fieldString(n.field) + n.text + boostString(n.boost)
+}
+
+// isPattern reports whether the word is a wildcard pattern.
+// A '?' at the end of a word is punctuation and no wildcard.
+func isPattern(word []byte) bool {
+	return bytes.IndexByte(bytes.TrimRight(word, "?"), '?') >= 0 ||
+		bytes.IndexByte(word, '*') >= 0
+}
+
+// lookupPattern returns the merged results of the keys of the field
+// whose segments match re and start with prefix and the termScore to use for them.
+// Only the maxExpansions keys with the most results are used
+// and each ID keeps its highest score.
+// If the prefix is empty, but a literal suffix is given,
+// the reversed keys are used to find the matching keys, if they exist.
+// The scan stops early if the context of the search is done.
+func (s *searcher) lookupPattern(field string, re *regexp.Regexp, prefix, suffix []byte) ([]Result, termScore) {
+	l := topKeyList{n: maxExpansions}
+	fieldPrefix := fieldPrefix(field)
+	n := 0
+	if len(prefix) == 0 && len(suffix) > 0 && s.reversed != nil {
+		reversedSuffix := reverseKey(suffix)
+		c := s.reversed.Cursor()
+		for k, _ := c.Seek(reversedSuffix); k != nil && bytes.HasPrefix(k, reversedSuffix); k, _ = c.Next() {
+			if n++; s.stopped(n) {
+				break
+			}
+			if key := reverseKey(k); inField(key, fieldPrefix) && re.Match(key[len(fieldPrefix):]) {
+				l.add(key, s.words.Get(key))
+			}
+		}
+	} else {
+		keyPrefix := fieldKey(field, prefix)
+		c := s.words.Cursor()
+		for k, v := c.Seek(keyPrefix); k != nil && bytes.HasPrefix(k, keyPrefix); k, v = c.Next() {
+			if n++; s.stopped(n) {
+				break
+			}
+			if inField(k, fieldPrefix) && re.Match(k[len(fieldPrefix):]) {
+				l.add(k, v)
+			}
+		}
+	}
+	var qr = make(map[ID]Score)
+	for _, key := range l.keys {
+		s.mergeMax(qr, key.key, 1)
+	}
+	return resultsOf(qr), resultScore
+}
+
+// reverseKey returns the key with its runes in reverse order.
+func reverseKey(key []byte) []byte {
+	reversed := make([]byte, len(key))
+	end := len(reversed)
+	for len(key) > 0 {
+		_, width := utf8.DecodeRune(key)
+		copy(reversed[end-width:end], key[:width])
+		end -= width
+		key = key[width:]
+	}
+	return reversed
+}
@@ -0,0 +1,64 @@
+package minsearch
+
+import (
+	"testing"
+)
+
+func TestSearchPattern(t *testing.T) {
+	for _, reversedKeys := range []bool{false, true} {
+		testSearchPattern(t, Options{ReversedKeys: reversedKeys})
+	}
+}
+
+func testSearchPattern(t *testing.T, options Options) {
+	f, cleanup := openTestFile(t, options)
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("Hamburg Meier")},
+		{ID: 2, Text: []byte("Magdeburg Maier")},
+		{ID: 3, Text: []byte("Burg Mayer")},
+		{ID: 4, Text: []byte("Burgdorf Müller")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string][]ID{
+		"*burg":           {2, 3},
+		"m??er":           {2, 3},
+		"m*er":            {2, 3, 4},
+		"/ma(i|y)er/":     {2, 3},
+		"/m.*/ -burg":     {2, 4},
+		"*urg* m*":        {2, 3, 4},
+		"*xyz":            {},
+		"mue??er burgdo*": {4},
+	}
+	for query, expected := range tests {
+		results, err := f.SearchQuery([]byte(query), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := sortedIDs(results); !equalIDs(ids, expected) {
+			t.Errorf("%+v: SearchQuery(%s) = %v; expected %v", options, query, ids, expected)
+		}
+	}
+}
+
+func TestLiteralPrefix(t *testing.T) {
+	var tests = map[string]string{
+		"st*sse":    "s",
+		"ste.*sse":  "ste",
+		"ma(i|y)er": "ma",
+		".*burg":    "",
+		"(?i)burg":  "",
+	}
+	for expr, expected := range tests {
+		if prefix := string(literalPrefix(expr)); prefix != expected {
+			t.Errorf("literalPrefix(%s) = %q; expected %q", expr, prefix, expected)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package minsearch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/boltdb/bolt"
+)
+
+// The positions bucket stores for each ID and segment the positions
+// of the segment among the relevant segments of the indexed texts.
+// The key is the ID followed by the segment and the value are the
+// uvarint encoded differences of the ascending positions.
+// The key consisting only of the ID stores the next free position of the ID,
+// so texts indexed later for the same ID don't overlap with earlier ones.
+
+func positionKey(id ID, segment []byte) []byte {
+	key := make([]byte, sizeID+len(segment))
+	binary.BigEndian.PutUint32(key, id)
+	copy(key[sizeID:], segment)
+	return key
+}
+
+// nextPosition returns the first position not used by any text indexed for the ID.
+func nextPosition(bucket *bolt.Bucket, id ID) uint32 {
+	data := bucket.Get(idKey(id))
+	if len(data) != 4 {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(data)
+}
+
+func setNextPosition(bucket *bolt.Bucket, id ID, position uint32) error {
+	var positionBytes [4]byte
+	binary.LittleEndian.PutUint32(positionBytes[:], position)
+	return bucket.Put(idKey(id), positionBytes[:])
+}
+
+// addPositions appends the ascending positions, each increased by offset,
+// to the positions of the segment for the ID.
+func addPositions(bucket *bolt.Bucket, id ID, segment []byte, offset uint32, positions []uint32) error {
+	key := positionKey(id, segment)
+	oldPositions := decodePositions(bucket.Get(key))
+	for _, position := range positions {
+		oldPositions = append(oldPositions, offset+position)
+	}
+	return bucket.Put(key, encodePositions(oldPositions))
+}
+
+func encodePositions(positions []uint32) []byte {
+	data := make([]byte, 0, len(positions))
+	var buf [binary.MaxVarintLen32]byte
+	var prev uint32
+	for _, position := range positions {
+		n := binary.PutUvarint(buf[:], uint64(position-prev))
+		data = append(data, buf[:n]...)
+		prev = position
+	}
+	return data
+}
+
+func decodePositions(data []byte) []uint32 {
+	var positions []uint32
+	var position uint32
+	for len(data) > 0 {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 {
+			break // corrupt data
+		}
+		position += uint32(delta)
+		positions = append(positions, position)
+		data = data[n:]
+	}
+	return positions
+}
+
+// deletePositions removes all positions stored for the ID.
+func deletePositions(bucket *bolt.Bucket, id ID) error {
+	prefix := idKey(id)
+	c := bucket.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Seek(prefix) {
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// phrasePositions returns the ascending positions where the
+// segments occur one after another for the ID.
+func phrasePositions(bucket *bolt.Bucket, id ID, segments [][]byte) []uint32 {
+	starts := decodePositions(bucket.Get(positionKey(id, segments[0])))
+	for offset, segment := range segments[1:] {
+		if len(starts) == 0 {
+			break
+		}
+		positions := decodePositions(bucket.Get(positionKey(id, segment)))
+		n := 0
+		for _, start := range starts {
+			next := start + uint32(offset) + 1
+			idx := sort.Search(len(positions), func(i int) bool { return positions[i] >= next })
+			if idx < len(positions) && positions[idx] == next {
+				starts[n] = start
+				n++
+			}
+		}
+		starts = starts[:n]
+	}
+	return starts
+}
+
+// isNear reports whether an occurrence of a and an occurrence of b are at most
+// distance positions apart, measured from the end of the earlier occurrence
+// to the start of the later one. a and b are the ascending start positions
+// of occurrences spanning lenA and lenB segments.
+func isNear(a []uint32, lenA uint32, b []uint32, lenB uint32, distance uint32) bool {
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if a[i] <= b[j] {
+			if b[j] <= a[i]+lenA-1+distance {
+				return true
+			}
+			i++
+		} else {
+			if a[i] <= b[j]+lenB-1+distance {
+				return true
+			}
+			j++
+		}
+	}
+	return false
+}
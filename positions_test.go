@@ -0,0 +1,53 @@
+package minsearch
+
+import (
+	"testing"
+)
+
+func TestSearchPhrase(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{Positions: true})
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("New York City")},
+		{ID: 2, Text: []byte("York is new")},
+		{ID: 3, Text: []byte("a new, old York")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.IndexPair(Pair{ID: 2, Text: []byte("York")}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string][]ID{
+		`"new york"`:             {1},
+		`"york new"`:             {},
+		`new NEAR/1 york`:        {1},
+		`new NEAR/2 york`:        {1, 2, 3},
+		`"new york" city`:        {1},
+		`"new york city"`:        {1},
+		`"new york" NEAR/1 city`: {1},
+		`city NEAR/1 "new york"`: {1},
+		`"york city" NEAR/1 new`: {1},
+		`"new york" NEAR/1 is`:   {},
+		`"city new"`:             {},
+		`new york`:               {1, 2, 3},
+	}
+	for query, expected := range tests {
+		results, err := f.SearchWithOptions([]byte(query), SearchOptions{SetOperation: Intersection, Syntax: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := sortedIDs(results); !equalIDs(ids, expected) {
+			t.Errorf("Search(%s) = %v; expected %v", query, ids, expected)
+		}
+	}
+
+	if err := f.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := f.SearchWithOptions([]byte(`"new york"`), SearchOptions{Syntax: true}); len(results) != 0 {
+		t.Errorf("Search after Delete = %v; expected no results", results)
+	}
+}
@@ -1,11 +1,14 @@
 package minsearch
 
 import (
+	"bytes"
+	"context"
+	"math"
 	"sort"
+	"unicode/utf8"
 	"unsafe"
 
 	"github.com/boltdb/bolt"
-	"github.com/tim-st/go-uniseg"
 )
 
 // SetOperation is the operation that is done on the result set
@@ -17,6 +20,9 @@ const (
 	Union SetOperation = iota
 	// Intersection collects all results that match each relevant segment of the query.
 	Intersection
+	// Difference collects all results that match the first relevant segment of the query
+	// but none of the other relevant segments.
+	Difference
 )
 
 // Result is a single search result of a result set.
@@ -33,59 +39,353 @@ const DefaultMaxResults = 1000000
 // and returns a result set ordered by score.
 // If maxResults > 0 the maximum temporary results _during_ calculation
 // of the search results, which can be much higher than the end result, are limited to maxResults.
-// If for at least one segment the number of results > maxResults it's possible
-// that the result set misses results with higher score.
+// Segments are searched starting with the segment that has the fewest results,
+// so an Intersection can only miss results if each segment has more than maxResults results.
+// A Union can miss results if more than maxResults results match any segment
+// and a Difference if more than maxResults results match its first segment.
+// Missed results can have a higher score than returned results (see SearchExact).
 // If maxResults <= 0 the memory is not limited.
 // It's recommend to set maxResults > 0 to limit the maximum RAM usage
 // (especially if the SetOperation is set to Union or query is user input).
+// The relevant segments of the query are searched literally in all fields of the File
+// (see Pair.Field), so user input can be searched unchanged; phrases, patterns and the other
+// query syntax are only parsed by SearchWithOptions if SearchOptions.Syntax is set.
+// The segments of the query are reduced by the Analyzer of the File like the indexed texts
+// (see Options.Analyzer).
 func (f *File) Search(query []byte, setOp SetOperation, maxResults int) ([]Result, error) {
-	var qr = make(map[ID]Score, 1024) // TODO: cap
+	return f.SearchWithOptions(query, SearchOptions{SetOperation: setOp, MaxResults: maxResults})
+}
+
+// SearchContext works like Search but stops searching if ctx is done
+// and returns ctx.Err() then. The context is checked between the segments of the query
+// and while all keys are compared for fuzzy segments and patterns.
+func (f *File) SearchContext(ctx context.Context, query []byte, setOp SetOperation, maxResults int) ([]Result, error) {
+	results, _, err := f.searchWithOptions(ctx, query, SearchOptions{SetOperation: setOp, MaxResults: maxResults})
+	return results, err
+}
+
+// SearchExact works like Search but also returns the number of leading results
+// that are exact: no result missed because of maxResults can have a higher score than them.
+// All returned results have their exact score, but if exact < len(results),
+// results with a lower score than results[exact-1] might be missing.
+// If exact == len(results) the result set is complete.
+func (f *File) SearchExact(query []byte, setOp SetOperation, maxResults int) (results []Result, exact int, err error) {
+	qr, bound, err := f.searchSet(context.Background(), query, setOp, maxResults)
+	results = resultsOf(qr)
+	sortResults(results)
+	return results, exactResults(results, bound), err
+}
+
+// exactResults returns the number of leading results that have
+// a higher score than the given upper bound of all missed results.
+// A bound of 0 means that no result was missed.
+func exactResults(results []Result, bound Score) int {
+	if bound == 0 {
+		return len(results)
+	}
+	return sort.Search(len(results), func(i int) bool {
+		return results[i].Score <= bound
+	})
+}
+
+// SearchFuzzy works like Search but also finds results of indexed segments
+// that differ from a segment of the query by an edit distance of at most maxDistance.
+// Insertions, deletions, substitutions and transpositions of adjacent runes count as a single edit.
+// Segments with less than 3 runes must match exactly and segments with less than 6 runes
+// may differ by at most 1 edit; a maxDistance greater than 2 is treated as 2.
+// The score of a result found by a segment with edit distance d is divided by 1+d
+// and if multiple similar segments match the same ID only the highest score is used.
+// All keys of the index must be compared for each segment, so SearchFuzzy is much slower than Search.
+// Phrases and segments combined by NEAR/n are not expanded.
+func (f *File) SearchFuzzy(query []byte, setOp SetOperation, maxDistance int, maxResults int) ([]Result, error) {
+	return f.SearchWithOptions(query, SearchOptions{SetOperation: setOp, MaxResults: maxResults, MaxDistance: maxDistance})
+}
+
+// termScore returns the score that a result of a segment adds to the search result.
+type termScore func(r Result) Score
+
+// searchSet returns the unordered result set of the query
+// and an upper bound of the scores of the results missed because of maxResults,
+// which is 0 if no result was missed.
+func (f *File) searchSet(ctx context.Context, query []byte, setOp SetOperation, maxResults int) (map[ID]Score, Score, error) {
+	var qr map[ID]Score
+	var bound Score
 	err := f.db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte{bucketWords})
-		segments := uniseg.Segments(query)
-		for _, segment := range segments {
-			element := normalizeSegment(segment)
-			if len(element) == 0 {
-				continue
+		s, terms, setOp := f.prepareSearch(tx, literalTerms(query), SearchOptions{SetOperation: setOp, MaxResults: maxResults})
+		var e error
+		qr, bound, e = s.searchSet(ctx, terms, setOp)
+		return e
+	})
+	return qr, bound, err
+}
+
+// searchSet returns the unordered result set of the terms
+// and an upper bound of the scores of the missed results like File.searchSet.
+// If ctx is done before all terms are searched, ctx.Err() is returned.
+// The scores are combined with the static boosts of the IDs.
+func (s *searcher) searchSet(ctx context.Context, terms []node, setOp SetOperation) (qr map[ID]Score, bound Score, err error) {
+	s.ctx = ctx
+	qr, bound, err = s.textSet(ctx, terms, setOp)
+	if err != nil || s.boosts == nil {
+		return qr, bound, err
+	}
+	applyBoosts(qr, s.boosts, s.boost)
+	if bound > 0 {
+		// a boost can increase the score of a missed result arbitrarily
+		bound = Score(math.Inf(1))
+	}
+	return qr, bound, nil
+}
+
+// textSet returns the result set of the terms like searchSet without the static boosts.
+func (s *searcher) textSet(ctx context.Context, terms []node, setOp SetOperation) (qr map[ID]Score, bound Score, err error) {
+	qr = make(map[ID]Score, 1024) // TODO: cap
+	maxResults := s.maxResults
+	if setOp == Union && s.minMatch == 0 {
+		// add the scores in the same order as unionTopK
+		terms = unionTerms(terms)
+	}
+	var lists []postingList
+	for _, term := range terms {
+		if err = ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		results, score := term.eval(s)
+		if err = ctx.Err(); err != nil {
+			// the scans of all keys stop early
+			return nil, 0, err
+		}
+		if len(results) == 0 && setOp == Intersection {
+			return qr, 0, nil
+		}
+		lists = append(lists, postingList{results: results, score: score})
+	}
+	if setOp != Difference {
+		// smaller lists fit completely into the result set and
+		// the smallest list limits an intersection the most
+		sortBySize(lists)
+	}
+	switch setOp {
+	case Union:
+		if s.minMatch > 0 {
+			bound = atLeast(lists, qr, maxResults, s.minMatch)
+			break
+		}
+		for _, list := range lists {
+			if err = ctx.Err(); err != nil {
+				return nil, 0, err
+			}
+			bound += union(list.results, qr, maxResults, list.score)
+		}
+	case Intersection:
+		for idx, list := range lists {
+			if err = ctx.Err(); err != nil {
+				return nil, 0, err
 			}
-			results := asResults(bucket.Get(element))
-			switch setOp {
-			case Union:
-				union(results, qr, maxResults)
-			case Intersection:
-				intersection(results, qr, maxResults)
-				if len(qr) == 0 {
-					return nil
+			skipped := intersection(list.results, qr, maxResults, list.score)
+			if skipped > 0 {
+				bound = skipped
+				for _, other := range lists[idx+1:] {
+					bound += maxScore(other.results, other.score)
 				}
 			}
+			if len(qr) == 0 {
+				return qr, bound, nil
+			}
 		}
-		return nil
-	})
+	case Difference:
+		for idx, list := range lists {
+			if err = ctx.Err(); err != nil {
+				return nil, 0, err
+			}
+			if idx == 0 {
+				bound = union(list.results, qr, maxResults, list.score)
+			} else {
+				difference(list.results, qr)
+			}
+			if len(qr) == 0 {
+				return qr, bound, nil
+			}
+		}
+	}
+	return qr, bound, nil
+}
+
+// contextCheckKeys is the number of keys a scan of all keys reads
+// between two checks whether the context of the search is done.
+const contextCheckKeys = 1024
+
+// searcher looks up the results of normalized segments during a search.
+type searcher struct {
+	// ctx stops the scans of all keys early if it's done
+	ctx       context.Context
+	words     *bolt.Bucket
+	positions *bolt.Bucket
+	reversed  *bolt.Bucket
+	// stats are the statistics of the fields that were looked up
+	stats      map[string]QueryStats
+	tx         *bolt.Tx
+	scorer     Scorer
+	maxResults int
+	// maxDistance is the maximum edit distance of fuzzy terms
+	maxDistance int
+	// minMatch is the number of terms each result of a Union must match;
+	// if minMatch > 0 the number of matched terms is added to the scores
+	minMatch int
+	// boosts are the static boosts of the IDs, which are combined with the
+	// scores of the result set by boost; nil if no boost is stored
+	boosts *bolt.Bucket
+	boost  BoostFunc
+	// merged is called with each key whose results are merged by mergeMax
+	// and the penalty of its scores, if it's not nil (see Explain)
+	merged func(key []byte, penalty Score)
+}
+
+func newSearcher(tx *bolt.Tx, scorer Scorer, maxResults int) *searcher {
+	return &searcher{
+		ctx:        context.Background(),
+		words:      tx.Bucket([]byte{bucketWords}),
+		positions:  tx.Bucket([]byte{bucketPositions}),
+		reversed:   tx.Bucket([]byte{bucketReversed}),
+		stats:      make(map[string]QueryStats),
+		tx:         tx,
+		scorer:     scorer,
+		maxResults: maxResults,
+		boosts:     boostsOf(tx),
+		boost:      MultiplyBoost,
+	}
+}
+
+// stopped reports whether a scan that read n keys must stop,
+// because the context of the search is done; it's only checked every contextCheckKeys keys.
+func (s *searcher) stopped(n int) bool {
+	return n%contextCheckKeys == 0 && s.ctx.Err() != nil
+}
+
+// lookup returns the results of the given key of a normalized segment (see fieldKey)
+// and the termScore to use for them.
+func (s *searcher) lookup(key []byte) ([]Result, termScore) {
+	results := asResults(s.words.Get(key))
+	field := keyField(key)
+	stats, ok := s.stats[field]
+	if !ok {
+		stats = newQueryStats(s.tx, field)
+		s.stats[field] = stats
+	}
+	stats.DocFreq = len(results)
+	return results, func(r Result) Score {
+		return s.scorer.QueryScore(r, stats)
+	}
+}
+
+// lookupFuzzy returns the merged results of all keys of the field within the allowed
+// edit distance of the given normalized segment and the termScore to use for them.
+// The score of a result is divided by 1 + distance and each ID keeps its highest score.
+// The scan stops early if the context of the search is done.
+func (s *searcher) lookupFuzzy(field string, segment []byte) ([]Result, termScore) {
+	segmentRunes := []rune(string(segment))
+	maxDistance := maxEditDistance(len(segmentRunes), s.maxDistance)
+	if maxDistance <= 0 {
+		return s.lookup(fieldKey(field, segment))
+	}
+	var qr = make(map[ID]Score)
+	prefix := fieldPrefix(field)
+	c := s.words.Cursor()
+	n := 0
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		if n++; s.stopped(n) {
+			break
+		}
+		if !inField(k, prefix) {
+			continue
+		}
+		key := k[len(prefix):]
+		if d := len(key) - len(segment); d > utf8.UTFMax*maxDistance || -d > utf8.UTFMax*maxDistance {
+			continue
+		}
+		distance := editDistance(segmentRunes, []rune(string(key)), maxDistance)
+		if distance > maxDistance {
+			continue
+		}
+		s.mergeMax(qr, k, 1+Score(distance))
+	}
+	return resultsOf(qr), resultScore
+}
+
+// lookupPrefix returns the merged results of the keys that start with the
+// given key of a normalized segment (see fieldKey) and the termScore to use for them.
+// Only the maxExpansions keys with the most results are used
+// and each ID keeps its highest score.
+func (s *searcher) lookupPrefix(prefix []byte) ([]Result, termScore) {
+	var qr = make(map[ID]Score)
+	for _, key := range topKeys(s.words, prefix, maxExpansions) {
+		s.mergeMax(qr, key.key, 1)
+	}
+	return resultsOf(qr), resultScore
+}
+
+// mergeMax merges the results of the key, whose scores are divided by penalty,
+// into the result set keeping the highest score per ID.
+func (s *searcher) mergeMax(qr map[ID]Score, key []byte, penalty Score) {
+	if s.merged != nil {
+		s.merged(key, penalty)
+	}
+	results, score := s.lookup(key)
+	for _, r := range results {
+		prevScore, exists := qr[r.ID]
+		if !exists && s.maxResults > 0 && len(qr) >= s.maxResults {
+			continue
+		}
+		if currentScore := score(r) / penalty; currentScore > prevScore {
+			qr[r.ID] = currentScore
+		}
+	}
+}
+
+// resultScore is the termScore of already scored results.
+func resultScore(r Result) Score {
+	return r.Score
+}
+
+// resultsOf returns the unordered results of the given result set.
+func resultsOf(qr map[ID]Score) []Result {
 	var results = make([]Result, 0, len(qr))
 	for id, score := range qr {
 		results = append(results, Result{ID: id, Score: score})
 	}
-	sortResults(results)
-	return results, err
+	return results
 }
 
-func union(results []Result, qr map[ID]Score, maxResults int) {
-	numberIDs := Score(len(results))
+// union adds the results to the result set. If the result set contains maxResults results,
+// only the scores of contained results are increased.
+// It returns the highest score of the skipped results, or 0 if no result was skipped.
+func union(results []Result, qr map[ID]Score, maxResults int, score termScore) Score {
+	var skipped Score
 	for _, r := range results {
-		if maxResults < 1 || len(qr) < maxResults {
-			qr[r.ID] += 1 + r.Score/numberIDs
+		if _, exists := qr[r.ID]; exists || maxResults < 1 || len(qr) < maxResults {
+			qr[r.ID] += score(r)
+		} else if current := score(r); current > skipped {
+			skipped = current
 		}
 	}
+	return skipped
 }
 
-func intersection(results []Result, qr map[ID]Score, maxResults int) {
+// intersection keeps only the results of the result set that are contained in results.
+// If the result set is empty, it's filled with at most maxResults results.
+// It returns the highest score of the results skipped then, or 0 if no result was skipped.
+func intersection(results []Result, qr map[ID]Score, maxResults int, score termScore) Score {
 	isFirst := len(qr) == 0
-	numberIDs := Score(len(results))
+	var skipped Score
 	for _, r := range results {
 		if prevScore, currentIDExists := qr[r.ID]; currentIDExists ||
 			(isFirst && (maxResults < 1 || len(qr) < maxResults)) {
-			currentScore := 1 + r.Score/numberIDs
+			currentScore := score(r)
 			qr[r.ID] = (prevScore + currentScore) * -1 // mark as matched again
+		} else if isFirst {
+			if current := score(r); current > skipped {
+				skipped = current
+			}
 		}
 	}
 
@@ -96,6 +396,25 @@ func intersection(results []Result, qr map[ID]Score, maxResults int) {
 			qr[id] *= -1 // unmark the matched elements
 		}
 	}
+	return skipped
+}
+
+// maxScore returns the highest score that one of the results adds.
+func maxScore(results []Result, score termScore) Score {
+	var max Score
+	for _, r := range results {
+		if current := score(r); current > max {
+			max = current
+		}
+	}
+	return max
+}
+
+// difference removes all results from the result set.
+func difference(results []Result, qr map[ID]Score) {
+	for _, r := range results {
+		delete(qr, r.ID)
+	}
 }
 
 // asResults casts the given byte slice to a Result slice.
@@ -112,11 +431,14 @@ func asResults(data []byte) []Result {
 
 func sortResults(results []Result) {
 	sort.Slice(results, func(i, j int) bool {
-		if results[i].Score == results[j].Score {
-			return results[i].ID < results[j].ID
-		}
-		return results[i].Score > results[j].Score // reversed
+		return isBetter(results[i], results[j])
 	})
 }
 
-// TODO: suggest keys by edit distance
+// isBetter reports whether a is ordered before b in a result set.
+func isBetter(a, b Result) bool {
+	if a.Score == b.Score {
+		return a.ID < b.ID
+	}
+	return a.Score > b.Score // reversed
+}
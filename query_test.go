@@ -0,0 +1,166 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearchDifference(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("Jaguar Auto")},
+		{ID: 2, Text: []byte("Jaguar Raubkatze")},
+		{ID: 3, Text: []byte("Auto")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := f.Search([]byte("jaguar auto"), Difference, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := resultIDs(results); !equalIDs(ids, []ID{2}) {
+		t.Errorf("Search(jaguar auto, Difference) = %v; expected [2]", ids)
+	}
+}
+
+func TestSearchLiteral(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("1/2 cup and 3/4")},
+		{ID: 2, Text: []byte("cup")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{"1/2 cup and 3/4", `"cup" AND 3*`} {
+		results, err := f.Search([]byte(query), Intersection, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := resultIDs(results); !equalIDs(ids, []ID{1}) {
+			t.Errorf("Search(%s) = %v; expected [1]", query, ids)
+		}
+	}
+}
+
+func TestSearchFuzzy(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("Berlin")},
+		{ID: 2, Text: []byte("Berlin Merlin")},
+		{ID: 3, Text: []byte("Berliner")},
+		{ID: 4, Text: []byte("Hamburg")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := f.SearchFuzzy([]byte("berlni"), Union, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := sortedIDs(results); !equalIDs(ids, []ID{1, 2}) {
+		t.Errorf("SearchFuzzy(berlni) = %v; expected [1 2]", ids)
+	}
+
+	results, err = f.SearchFuzzy([]byte("berlin"), Union, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := resultIDs(results); !equalIDs(ids, []ID{1, 2}) {
+		t.Errorf("SearchFuzzy(berlin) = %v; expected [1 2]", ids)
+	}
+}
+
+func TestSearchExact(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	var pairs []Pair
+	for id := ID(1); id <= 50; id++ {
+		text := []byte("berlin")
+		for i := ID(0); i < id%7; i++ {
+			text = append(text, " kreuzberg"...)
+		}
+		if id%10 == 0 {
+			text = append(text, " mauer"...)
+		}
+		pairs = append(pairs, Pair{ID: id, Text: text})
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// the intersection starts with the 5 results of mauer
+	results, exact, err := f.SearchExact([]byte("berlin mauer"), Intersection, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalIDs(sortedIDs(results), []ID{10, 20, 30, 40, 50}) || exact != len(results) {
+		t.Errorf("SearchExact(Intersection) = %v, %d; expected IDs 10 to 50 and all exact", results, exact)
+	}
+
+	all, err := f.Search([]byte("berlin mauer"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, exact, err = f.SearchExact([]byte("berlin mauer"), Union, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 20 || exact == 0 || exact >= len(results) {
+		t.Fatalf("SearchExact(Union) returned %d results, %d exact; expected 20, some exact", len(results), exact)
+	}
+	if fmt.Sprint(results[:exact]) != fmt.Sprint(all[:exact]) {
+		t.Errorf("SearchExact(Union) exact results = %v; expected %v", results[:exact], all[:exact])
+	}
+
+	// the segments are searched in all fields
+	if err := f.IndexPair(Pair{ID: 51, Text: []byte("Mauer"), Field: "title"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	results, exact, err = f.SearchExact([]byte("mauer"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalIDs(sortedIDs(results), []ID{10, 20, 30, 40, 50, 51}) || exact != len(results) {
+		t.Errorf("SearchExact(mauer) = %v, %d; expected IDs 10 to 51 and all exact", results, exact)
+	}
+
+	// the union of a segment's fields is not limited by maxResults
+	for id := ID(52); id <= 54; id++ {
+		if err := f.IndexPair(Pair{ID: id, Text: []byte("Berlin"), Field: "title"}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all, err = f.Search([]byte("berlin"), Intersection, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, exact, err = f.SearchExact([]byte("berlin"), Intersection, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exact == len(results) && fmt.Sprint(results) != fmt.Sprint(all) {
+		t.Errorf("SearchExact(berlin) = %v, all exact; expected %v", results, all)
+	}
+	if fmt.Sprint(results[:exact]) != fmt.Sprint(all[:exact]) {
+		t.Errorf("SearchExact(berlin) exact results = %v; expected %v", results[:exact], all[:exact])
+	}
+	results, exact, err = f.SearchExact([]byte("berlin mauer"), Intersection, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalIDs(sortedIDs(results), []ID{10, 20, 30, 40, 50}) || exact != len(results) {
+		t.Errorf("SearchExact(berlin mauer) = %v, %d; expected IDs 10 to 50 and all exact", results, exact)
+	}
+}
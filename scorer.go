@@ -0,0 +1,153 @@
+package minsearch
+
+import (
+	"errors"
+
+	"github.com/boltdb/bolt"
+)
+
+const dbStatsScorer = `scorer`
+
+// kinds of index scores stored in a File
+const (
+	defaultScores       byte = iota // scores of DefaultScorer or another Scorer
+	termFrequencyScores             // term frequencies of BM25
+)
+
+// ErrScorerMismatch is returned if a File indexed with BM25 is opened or searched
+// with another Scorer or a File indexed with another Scorer with BM25.
+var ErrScorerMismatch = errors.New("minsearch: the File was indexed with another kind of scorer")
+
+// Scorer calculates the scores while indexing and searching.
+// A File should always be searched with a Scorer whose
+// IndexScore matches the one the File was indexed with.
+// The File stores whether it was indexed with BM25, whose index scores
+// are term frequencies, so BM25 and the other Scorers can't be mixed up
+// (see Options.Scorer).
+// A Scorer whose QueryScore never decreases when the Score of the Result
+// increases should also implement MonotoneScorer.
+type Scorer interface {
+	// IndexScore returns the Score stored for a segment that occurs
+	// count times in a text consisting of length segments.
+	// Results of a segment are kept sorted by this Score, so if maxIDs > 0
+	// only the results with the highest IndexScore are kept.
+	IndexScore(count, length int) Score
+	// QueryScore returns the score that the Result r of a segment's result list
+	// adds to the score of the search result with the same ID.
+	// The returned score must be > 0.
+	QueryScore(r Result, stats QueryStats) Score
+}
+
+// MonotoneScorer is implemented by a Scorer that can report whether its QueryScore
+// never decreases when the Score of the Result increases (for the same QueryStats).
+// The posting lists are ordered by the scores such a Scorer adds,
+// which allows SearchTopK and SearchWithOptions to stop reading them early
+// and SearchIter to read a single segment lazily.
+type MonotoneScorer interface {
+	Scorer
+	// Monotone reports whether QueryScore never decreases with the Score of the Result.
+	Monotone() bool
+}
+
+// QueryStats are the statistics of a query segment and
+// the indexed documents available to a Scorer during a search.
+type QueryStats struct {
+	// DocFreq is the number of results of the segment.
+	DocFreq int
+	// DocCount is the number of documents with a text in the field of the segment.
+	DocCount int
+	// AvgLength is the average length of the texts in the field of the segment.
+	AvgLength float32
+	// KeyCount is the number of keys of the index and AvgDocFreq the average
+	// number of results per key at the last call of UpdateStatistics.
+	// Both are 0 if the statistics were never calculated.
+	KeyCount   int
+	AvgDocFreq float32
+
+	lengths *bolt.Bucket
+	field   string
+}
+
+// DocLength returns the length of the longest text indexed for the given ID
+// in the field of the segment, which is its number of relevant segments.
+// If the length is unknown, 0 is returned.
+func (s QueryStats) DocLength(id ID) int {
+	if s.lengths == nil {
+		return 0
+	}
+	return int(docLength(s.lengths, s.field, id))
+}
+
+// newQueryStats returns the statistics of the segments of the given field.
+func newQueryStats(tx *bolt.Tx, field string) QueryStats {
+	c := readCollection(tx, field)
+	keyCount, avgCount := readKeyStatistics(tx)
+	return QueryStats{
+		DocCount:   int(c.docCount),
+		AvgLength:  c.avgLength(),
+		KeyCount:   int(keyCount),
+		AvgDocFreq: avgCount,
+		lengths:    tx.Bucket([]byte{bucketLengths}),
+		field:      field,
+	}
+}
+
+// scoresOf returns the kind of index scores the Scorer stores.
+func scoresOf(scorer Scorer) byte {
+	switch scorer.(type) {
+	case BM25, *BM25:
+		return termFrequencyScores
+	}
+	return defaultScores
+}
+
+// openScorer returns the Scorer of a File opened with the given Scorer, which may be nil,
+// and stores the kind of its index scores, if the File is new.
+// If Scorer is nil, the Scorer is chosen by the stored kind of index scores.
+func openScorer(tx *bolt.Tx, scorer Scorer, isEmpty bool) (Scorer, error) {
+	stats := tx.Bucket([]byte{bucketStats})
+	stored := defaultScores
+	if data := stats.Get([]byte(dbStatsScorer)); len(data) == 1 {
+		stored = data[0]
+	}
+	if scorer == nil {
+		switch stored {
+		case defaultScores:
+			return DefaultScorer{}, nil
+		case termFrequencyScores:
+			return BM25{K1: 1.2, B: 0.75}, nil
+		}
+		return nil, ErrScorerMismatch
+	}
+	scores := scoresOf(scorer)
+	switch {
+	case scores == stored:
+		return scorer, nil
+	case !isEmpty:
+		return nil, ErrScorerMismatch
+	case scores == defaultScores:
+		return scorer, stats.Delete([]byte(dbStatsScorer))
+	}
+	return scorer, stats.Put([]byte(dbStatsScorer), []byte{scores})
+}
+
+// DefaultScorer is the Scorer used if no other Scorer is set.
+// The index score is 1 + count/length and a result adds
+// 1 + Score/DocFreq to the search result.
+type DefaultScorer struct{}
+
+// IndexScore implements Scorer.
+func (DefaultScorer) IndexScore(count, length int) Score {
+	return 1 + Score(count)/Score(length)
+}
+
+// QueryScore implements Scorer.
+func (DefaultScorer) QueryScore(r Result, stats QueryStats) Score {
+	return 1 + r.Score/Score(stats.DocFreq)
+}
+
+// Monotone implements MonotoneScorer:
+// QueryScore increases with the Score of the Result.
+func (DefaultScorer) Monotone() bool {
+	return true
+}
@@ -0,0 +1,33 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+type countScorer struct{ DefaultScorer }
+
+func (countScorer) QueryScore(Result, QueryStats) Score { return 1 }
+
+func TestScorer(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{Scorer: countScorer{}})
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("Berlin")},
+		{ID: 2, Text: []byte("Berlin Mauer Museum")},
+		{ID: 3, Text: []byte("Mauer")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := f.Search([]byte("berlin mauer museum"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Result{{ID: 2, Score: 3}, {ID: 1, Score: 1}, {ID: 3, Score: 1}}
+	if fmt.Sprint(results) != fmt.Sprint(expected) {
+		t.Errorf("Search with countScorer = %v; expected %v", results, expected)
+	}
+}
@@ -76,6 +76,19 @@ func (f *File) KeyCount() (uint32, error) {
 	return keyCount, err
 }
 
+// readKeyStatistics returns the statistics stored by UpdateStatistics,
+// which are 0 if they were never calculated.
+func readKeyStatistics(tx *bolt.Tx) (keyCount uint32, avgCount float32) {
+	bucket := tx.Bucket([]byte{bucketStats})
+	if data := bucket.Get([]byte(dbStatsKeyCount)); len(data) == 4 {
+		keyCount = binary.LittleEndian.Uint32(data)
+	}
+	if data := bucket.Get([]byte(dbStatsAvgCount)); len(data) == 4 {
+		avgCount = math.Float32frombits(binary.LittleEndian.Uint32(data))
+	}
+	return keyCount, avgCount
+}
+
 // UpdateStatistics calculates the current number of keys and the average data length.
 func (f *File) UpdateStatistics() error {
 	return f.db.Update(func(tx *bolt.Tx) error {
@@ -0,0 +1,223 @@
+package minsearch
+
+// germanStem returns the stem of a normalized German segment
+// using a light stemmer that only removes common inflectional suffixes.
+// The umlauts, which are replaced by "ae", "oe" and "ue" while normalizing,
+// are reduced to their vowels first, so "haeuser" and "haus" get the same stem.
+func germanStem(segment []byte) []byte {
+	word := foldUmlauts([]rune(string(segment)))
+	word = germanStep1(word)
+	word = germanStep2(word)
+	return []byte(string(word))
+}
+
+// foldUmlauts removes the 'e' of the "ae", "oe" and "ue" in the word
+// that are probably replaced umlauts: "ue" only after a consonant
+// or at the start of the word, so "feuer" and "quelle" are kept.
+func foldUmlauts(word []rune) []rune {
+	const (
+		consonant = iota // the next "ue" is an umlaut
+		umlaut           // the next 'e' is part of an umlaut
+		vowel
+	)
+	folded := word[:0]
+	state := consonant
+	for _, r := range word {
+		switch r {
+		case 'a', 'o':
+			state = umlaut
+		case 'u':
+			if state == consonant {
+				state = umlaut
+			} else {
+				state = vowel
+			}
+		case 'e':
+			isUmlaut := state == umlaut
+			state = vowel
+			if isUmlaut {
+				continue
+			}
+		case 'i', 'q', 'y':
+			state = vowel
+		default:
+			state = consonant
+		}
+		folded = append(folded, r)
+	}
+	return folded
+}
+
+// germanStep1 removes the suffixes "ern", "em", "en", "er", "es", "e"
+// and 's' after a consonant that can end a German stem.
+func germanStep1(word []rune) []rune {
+	n := len(word)
+	switch {
+	case n > 5 && hasSuffix(word, "ern"):
+		return word[:n-3]
+	case n > 4 && word[n-2] == 'e' && (word[n-1] == 'm' || word[n-1] == 'n' || word[n-1] == 'r' || word[n-1] == 's'):
+		return word[:n-2]
+	case n > 3 && word[n-1] == 'e':
+		return word[:n-1]
+	case n > 3 && word[n-1] == 's' && isGermanSEnding(word[n-2]):
+		return word[:n-1]
+	}
+	return word
+}
+
+// germanStep2 removes the suffixes "est", "er", "en"
+// and "st" after a consonant that can end a German stem.
+func germanStep2(word []rune) []rune {
+	n := len(word)
+	switch {
+	case n > 5 && hasSuffix(word, "est"):
+		return word[:n-3]
+	case n > 4 && word[n-2] == 'e' && (word[n-1] == 'r' || word[n-1] == 'n'):
+		return word[:n-2]
+	case n > 4 && hasSuffix(word, "st") && word[n-3] != 'r' && isGermanSEnding(word[n-3]):
+		return word[:n-2]
+	}
+	return word
+}
+
+// isGermanSEnding reports whether an inflectional 's' can follow the rune.
+func isGermanSEnding(r rune) bool {
+	switch r {
+	case 'b', 'd', 'f', 'g', 'h', 'k', 'l', 'm', 'n', 'r', 't':
+		return true
+	}
+	return false
+}
+
+// hasSuffix reports whether the word ends with the ASCII suffix.
+func hasSuffix(word []rune, suffix string) bool {
+	if len(word) < len(suffix) {
+		return false
+	}
+	for idx := range suffix {
+		if word[len(word)-len(suffix)+idx] != rune(suffix[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// englishStem returns the stem of a normalized English segment
+// using the first step of the Porter stemmer, which removes plurals
+// and the suffixes "ed" and "ing", so "running" and "runs" get the stem "run".
+// Segments with other runes than 'a' to 'z' are returned unchanged.
+func englishStem(segment []byte) []byte {
+	if len(segment) <= 2 {
+		return segment
+	}
+	for _, c := range segment {
+		if c < 'a' || c > 'z' {
+			return segment
+		}
+	}
+	word := append([]byte(nil), segment...)
+	word = englishStep1a(word)
+	word = englishStep1b(word)
+	return englishStep1c(word)
+}
+
+// englishStep1a removes plurals: "sses" -> "ss", "ies" -> "i", "s" -> "" (but not "ss").
+func englishStep1a(word []byte) []byte {
+	switch {
+	case hasByteSuffix(word, "sses"), hasByteSuffix(word, "ies"):
+		return word[:len(word)-2]
+	case hasByteSuffix(word, "ss"):
+		return word
+	case hasByteSuffix(word, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// englishStep1b removes the suffixes "eed", "ed" and "ing" and repairs the stem,
+// so "hoping" becomes "hope" and "hopping" becomes "hop".
+func englishStep1b(word []byte) []byte {
+	if hasByteSuffix(word, "eed") {
+		if measure(word[:len(word)-3]) > 0 {
+			return word[:len(word)-1]
+		}
+		return word
+	}
+	var stem []byte
+	switch {
+	case hasByteSuffix(word, "ed") && hasVowel(word[:len(word)-2]):
+		stem = word[:len(word)-2]
+	case hasByteSuffix(word, "ing") && hasVowel(word[:len(word)-3]):
+		stem = word[:len(word)-3]
+	default:
+		return word
+	}
+	n := len(stem)
+	switch {
+	case hasByteSuffix(stem, "at"), hasByteSuffix(stem, "bl"), hasByteSuffix(stem, "iz"):
+		return append(stem, 'e')
+	case n >= 2 && stem[n-1] == stem[n-2] && isConsonant(stem, n-1) &&
+		stem[n-1] != 'l' && stem[n-1] != 's' && stem[n-1] != 'z':
+		return stem[:n-1]
+	case measure(stem) == 1 && endsCVC(stem):
+		return append(stem, 'e')
+	}
+	return stem
+}
+
+// englishStep1c replaces a final 'y' by 'i' if the stem contains a vowel.
+func englishStep1c(word []byte) []byte {
+	if n := len(word); word[n-1] == 'y' && hasVowel(word[:n-1]) {
+		word[n-1] = 'i'
+	}
+	return word
+}
+
+// isConsonant reports whether the letter at the index is a consonant.
+// 'y' is a consonant at the start of the word and after a vowel.
+func isConsonant(word []byte, idx int) bool {
+	switch word[idx] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return idx == 0 || !isConsonant(word, idx-1)
+	}
+	return true
+}
+
+// measure returns the number of vowel-consonant sequences of the word.
+func measure(word []byte) int {
+	var m int
+	for idx := 1; idx < len(word); idx++ {
+		if isConsonant(word, idx) && !isConsonant(word, idx-1) {
+			m++
+		}
+	}
+	return m
+}
+
+// hasVowel reports whether the word contains a vowel.
+func hasVowel(word []byte) bool {
+	for idx := range word {
+		if !isConsonant(word, idx) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsCVC reports whether the word ends with consonant-vowel-consonant
+// where the last consonant is not 'w', 'x' or 'y', like "hop".
+func endsCVC(word []byte) bool {
+	n := len(word)
+	if n < 3 || !isConsonant(word, n-3) || isConsonant(word, n-2) || !isConsonant(word, n-1) {
+		return false
+	}
+	last := word[n-1]
+	return last != 'w' && last != 'x' && last != 'y'
+}
+
+// hasByteSuffix reports whether the word ends with the suffix.
+func hasByteSuffix(word []byte, suffix string) bool {
+	return len(word) >= len(suffix) && string(word[len(word)-len(suffix):]) == suffix
+}
@@ -0,0 +1,44 @@
+package minsearch
+
+import (
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	var tests = []struct {
+		analyzer Analyzer
+		input    string
+		expected string
+	}{
+		{DefaultAnalyzer, "Häuser", "haeuser"},
+		{GermanAnalyzer, "Häuser", "haus"},
+		{GermanAnalyzer, "Haus", "haus"},
+		{GermanAnalyzer, "Hauses", "haus"},
+		{GermanAnalyzer, "Kindern", "kind"},
+		{GermanAnalyzer, "Kinder", "kind"},
+		{GermanAnalyzer, "schönsten", "schon"},
+		{GermanAnalyzer, "Feuer", "feu"},
+		{GermanAnalyzer, "Quelle", "quell"},
+		{GermanAnalyzer, "Bus", "bus"},
+		{EnglishAnalyzer, "running", "run"},
+		{EnglishAnalyzer, "runs", "run"},
+		{EnglishAnalyzer, "run", "run"},
+		{EnglishAnalyzer, "caresses", "caress"},
+		{EnglishAnalyzer, "ponies", "poni"},
+		{EnglishAnalyzer, "pony", "poni"},
+		{EnglishAnalyzer, "agreed", "agree"},
+		{EnglishAnalyzer, "feed", "feed"},
+		{EnglishAnalyzer, "hoping", "hope"},
+		{EnglishAnalyzer, "hopping", "hop"},
+		{EnglishAnalyzer, "falling", "fall"},
+		{EnglishAnalyzer, "conflated", "conflate"},
+		{EnglishAnalyzer, "sing", "sing"},
+		{EnglishAnalyzer, "is", "is"},
+	}
+
+	for _, test := range tests {
+		if got := string(test.analyzer.stem([]byte(normalizeString(test.input)))); got != test.expected {
+			t.Errorf("Analyzer(%d).stem(%s) = %s; expected %s", test.analyzer, test.input, got, test.expected)
+		}
+	}
+}
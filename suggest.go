@@ -0,0 +1,168 @@
+package minsearch
+
+import (
+	"context"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/boltdb/bolt"
+)
+
+// maxSuggestDistance is the maximum edit distance of a suggested segment.
+const maxSuggestDistance = 2
+
+// suggestion is a corrected segment or query.
+type suggestion struct {
+	text     string
+	distance int // edit distance to the original
+	docFreq  int // number of results; of a query the sum over its segments
+}
+
+func sortSuggestions(suggestions []suggestion) {
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.docFreq != b.docFreq {
+			return a.docFreq > b.docFreq
+		}
+		return a.text < b.text
+	})
+}
+
+// Suggest returns up to n corrected versions of the query ("did you mean")
+// built from the normalized relevant segments of the query.
+// Each segment that has no results in the index is replaced by indexed segments
+// with a small edit distance (see SearchFuzzy).
+// Corrections are ranked by their total edit distance first
+// and by the number of results of their segments second.
+// If all segments have results or no correction is found, nil is returned.
+// If the File has an Analyzer (see Options.Analyzer), the segments are reduced by it
+// before they are compared with the indexed segments and the corrections
+// are reduced segments, like "poni" for "ponie"; segments with results are kept unchanged.
+// All keys of the index are compared, so Suggest should only be used
+// if a search has no or too few results.
+func (f *File) Suggest(query []byte, n int) ([]string, error) {
+	return f.SuggestContext(context.Background(), query, n)
+}
+
+// SuggestContext works like Suggest but stops comparing the keys of the index
+// if ctx is done and returns ctx.Err() then.
+func (f *File) SuggestContext(ctx context.Context, query []byte, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	var corrections []string
+	err := f.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte{bucketWords})
+		fields := append([]string{""}, readFields(tx)...)
+		segments := normalizeQuery(query)
+		// stems[idx] is segments[idx] reduced like the indexed segments
+		stems := make([][]byte, len(segments))
+		for idx, segment := range segments {
+			stems[idx] = f.analyzer.stem(segment)
+		}
+
+		// candidates[idx] are the possible corrections of segments[idx]
+		candidates := make([][]suggestion, len(segments))
+		var missing []int
+		for idx, segment := range segments {
+			var docFreq int
+			for _, field := range fields {
+				docFreq += len(bucket.Get(fieldKey(field, stems[idx]))) / sizeResult
+			}
+			if docFreq > 0 {
+				candidates[idx] = []suggestion{{text: string(segment), docFreq: docFreq}}
+			} else {
+				missing = append(missing, idx)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+
+		// the segments of all fields are compared; found[idx] maps
+		// a corrected segment to its index in candidates[idx]
+		found := make([]map[string]int, len(segments))
+		c := bucket.Cursor()
+		keys := 0
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if keys++; keys%contextCheckKeys == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			key := keySegment(k)
+			for _, idx := range missing {
+				segment := stems[idx]
+				maxDistance := maxEditDistance(utf8.RuneCount(segment), maxSuggestDistance)
+				if d := len(key) - len(segment); maxDistance <= 0 ||
+					d > utf8.UTFMax*maxDistance || -d > utf8.UTFMax*maxDistance {
+					continue
+				}
+				distance := editDistance([]rune(string(segment)), []rune(string(key)), maxDistance)
+				if distance > maxDistance {
+					continue
+				}
+				if found[idx] == nil {
+					found[idx] = make(map[string]int)
+				}
+				if pos, exists := found[idx][string(key)]; exists {
+					candidates[idx][pos].docFreq += len(v) / sizeResult
+					continue
+				}
+				found[idx][string(key)] = len(candidates[idx])
+				candidates[idx] = append(candidates[idx], suggestion{
+					text: string(key), distance: distance, docFreq: len(v) / sizeResult})
+			}
+		}
+
+		corrected := false
+		for _, idx := range missing {
+			if len(candidates[idx]) == 0 {
+				// keep the segment, so the other segments can still be corrected
+				candidates[idx] = []suggestion{{text: string(segments[idx])}}
+				continue
+			}
+			corrected = true
+			sortSuggestions(candidates[idx])
+			if len(candidates[idx]) > n {
+				candidates[idx] = candidates[idx][:n]
+			}
+		}
+		if !corrected {
+			return nil
+		}
+
+		// combine the candidates of all segments keeping the best n queries
+		queries := []suggestion{{}}
+		for _, segmentCandidates := range candidates {
+			var next []suggestion
+			for _, q := range queries {
+				for _, candidate := range segmentCandidates {
+					text := candidate.text
+					if len(q.text) > 0 {
+						text = q.text + " " + text
+					}
+					next = append(next, suggestion{
+						text:     text,
+						distance: q.distance + candidate.distance,
+						docFreq:  q.docFreq + candidate.docFreq,
+					})
+				}
+			}
+			sortSuggestions(next)
+			if len(next) > n {
+				next = next[:n]
+			}
+			queries = next
+		}
+
+		for _, q := range queries {
+			corrections = append(corrections, q.text)
+		}
+		return nil
+	})
+	return corrections, err
+}
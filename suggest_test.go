@@ -0,0 +1,37 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	err := f.IndexBatch([]Pair{
+		{ID: 1, Text: []byte("Berlin Mauer")},
+		{ID: 2, Text: []byte("Berlin Merlin")},
+		{ID: 3, Text: []byte("Bauer")},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]string{
+		"Berlin":            "[]",
+		"berlni mauer":      "[berlin mauer merlin mauer]",
+		"Berlin Mauerr":     "[berlin mauer berlin bauer]",
+		"berlin xyzxyz":     "[]",
+		"berlin mauerr xyz": "[berlin mauer xyz berlin bauer xyz]",
+	}
+	for query, expected := range tests {
+		suggestions, err := f.Suggest([]byte(query), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(suggestions); got != expected {
+			t.Errorf("Suggest(%s) = %s; expected %s", query, got, expected)
+		}
+	}
+}
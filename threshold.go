@@ -0,0 +1,215 @@
+package minsearch
+
+import (
+	"sort"
+)
+
+// firstPruneRound is the first round of unionTopK that checks whether it can stop.
+// The following checks are done after twice as many rounds as the previous check,
+// which limits the cost of the checks and reads at most twice as many results as necessary.
+const firstPruneRound = 64
+
+// postingList is the result list of a term of the query,
+// which is ordered by descending score.
+type postingList struct {
+	results []Result
+	score   termScore
+}
+
+// isMonotone reports whether the QueryScore of the Scorer doesn't decrease with
+// the Score of a Result, so posting lists are also ordered by the scores they add.
+func isMonotone(scorer Scorer) bool {
+	m, ok := scorer.(MonotoneScorer)
+	return ok && m.Monotone()
+}
+
+// postingLists returns the ordered posting lists of the terms of a Union.
+// The children of unions, like the fields of a term (see expandFields),
+// get posting lists of their own (see unionTerms).
+func postingLists(s *searcher, terms []node) []postingList {
+	var lists []postingList
+	for _, term := range unionTerms(terms) {
+		results, score := term.eval(s)
+		if !sort.SliceIsSorted(results, func(i, j int) bool {
+			return results[i].Score > results[j].Score
+		}) {
+			// only results merged by eval are unordered, which can be modified
+			sortResults(results)
+		}
+		lists = append(lists, postingList{results: results, score: score})
+	}
+	sortBySize(lists) // add the scores in the same order as searchSet
+	return lists
+}
+
+// unionTerms returns the terms whose results are added up by a Union,
+// where each union is replaced by its children: their scores are added up, too,
+// so the results of each child needn't be merged into an unordered result set first.
+func unionTerms(terms []node) []node {
+	var flat []node
+	for _, term := range terms {
+		op, isOp := term.(opNode)
+		if !isOp || op.op != Union {
+			flat = append(flat, term)
+			continue
+		}
+		for _, child := range op.children {
+			if _, isNot := child.(notNode); !isNot { // excluding from a union has no meaning
+				flat = append(flat, unionTerms([]node{child})...)
+			}
+		}
+	}
+	return flat
+}
+
+// sortBySize orders the posting lists by ascending number of results.
+func sortBySize(lists []postingList) {
+	sort.SliceStable(lists, func(i, j int) bool {
+		return len(lists[i].results) < len(lists[j].results)
+	})
+}
+
+// candidate is a result of unionTopK whose score is only known
+// for the posting lists that were read up to the result.
+type candidate struct {
+	scores []Score // scores[i] is the score added by list i; 0 if unknown
+}
+
+// lowerBound returns the sum of the known scores.
+func (c *candidate) lowerBound() Score {
+	var sum Score
+	for _, score := range c.scores {
+		sum += score
+	}
+	return sum
+}
+
+// upperBound returns the highest possible score of the candidate,
+// if the unknown scores are at most the given scores.
+func (c *candidate) upperBound(unknown []Score) Score {
+	var sum Score
+	for idx, score := range c.scores {
+		if score == 0 {
+			score = unknown[idx]
+		}
+		sum += score
+	}
+	return sum
+}
+
+// unionTopK returns the k best results of the union of the posting lists
+// like topResults would return them for the result set of union.
+// It reads the lists in parallel using the no random access algorithm (NRA):
+// the last score read from each list limits the scores of all unread results,
+// so reading stops as soon as no unread or incomplete result can reach the k best results.
+// Afterwards only the unread parts of lists missing for one of the k best results are scanned.
+func unionTopK(lists []postingList, k int) []Result {
+	candidates := make(map[ID]*candidate)
+	last := make([]Score, len(lists)) // upper bound of the unread scores per list
+	pos := 0
+	nextCheck := firstPruneRound
+	for {
+		active := false
+		for idx, list := range lists {
+			if pos >= len(list.results) {
+				last[idx] = 0
+				continue
+			}
+			active = true
+			r := list.results[pos]
+			score := list.score(r)
+			last[idx] = score
+			c, exists := candidates[r.ID]
+			if !exists {
+				c = &candidate{scores: make([]Score, len(lists))}
+				candidates[r.ID] = c
+			}
+			if c.scores[idx] == 0 { // a list contains each ID at most once
+				c.scores[idx] = score
+			}
+		}
+		pos++
+		if !active {
+			break
+		}
+		if pos == nextCheck {
+			nextCheck *= 2
+			if top := prunedTopK(candidates, last, k); top != nil {
+				return completeTopK(top, candidates, lists, pos, k)
+			}
+		}
+	}
+
+	// all lists are read completely, so all scores are known
+	qr := make(map[ID]Score, len(candidates))
+	for id, c := range candidates {
+		qr[id] = c.lowerBound()
+	}
+	return topResults(qr, k)
+}
+
+// prunedTopK returns the IDs of the k best candidates, if no other
+// candidate and no unread result can reach them; otherwise nil.
+func prunedTopK(candidates map[ID]*candidate, last []Score, k int) []ID {
+	if len(candidates) < k {
+		return nil
+	}
+	lowerBounds := make(map[ID]Score, len(candidates))
+	for id, c := range candidates {
+		lowerBounds[id] = c.lowerBound()
+	}
+	top := topResults(lowerBounds, k)
+	kth := top[len(top)-1].Score
+
+	var unread Score
+	for _, score := range last {
+		unread += score
+	}
+	if unread >= kth {
+		return nil
+	}
+	isTop := make(map[ID]bool, len(top))
+	for _, r := range top {
+		isTop[r.ID] = true
+	}
+	for id, c := range candidates {
+		if !isTop[id] && c.upperBound(last) >= kth {
+			return nil
+		}
+	}
+	ids := make([]ID, len(top))
+	for idx, r := range top {
+		ids[idx] = r.ID
+	}
+	return ids
+}
+
+// completeTopK calculates the exact scores of the given best candidates
+// by scanning the unread parts of the lists for missing scores.
+func completeTopK(top []ID, candidates map[ID]*candidate, lists []postingList, pos, k int) []Result {
+	for idx, list := range lists {
+		if pos >= len(list.results) {
+			continue
+		}
+		missing := make(map[ID]*candidate)
+		for _, id := range top {
+			if c := candidates[id]; c.scores[idx] == 0 {
+				missing[id] = c
+			}
+		}
+		for _, r := range list.results[pos:] {
+			if len(missing) == 0 {
+				break
+			}
+			if c, found := missing[r.ID]; found {
+				c.scores[idx] = list.score(r)
+				delete(missing, r.ID)
+			}
+		}
+	}
+	qr := make(map[ID]Score, len(top))
+	for _, id := range top {
+		qr[id] = candidates[id].lowerBound()
+	}
+	return topResults(qr, k)
+}
@@ -0,0 +1,79 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestSearchTopKPruned(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	var pairs []Pair
+	for id := ID(1); id <= 3000; id++ {
+		var text []byte
+		for i := ID(0); i < id%13; i++ {
+			text = append(text, "berlin "...)
+		}
+		for i := ID(0); i < id%5; i++ {
+			text = append(text, "mauer "...)
+		}
+		if id%3 == 0 {
+			text = append(text, "museum"...)
+		}
+		text = append(text, " Text"...)
+		pairs = append(pairs, Pair{ID: id, Text: text})
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	testSearchTopKPruned(t, f, SearchOptions{Syntax: true})
+
+	// each field of a term is read as a posting list of its own
+	pairs = pairs[:0]
+	for id := ID(7); id <= 3000; id += 7 {
+		pairs = append(pairs, Pair{ID: id, Text: []byte("Berlin Museum"), Field: "title"})
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+	testSearchTopKPruned(t, f, SearchOptions{Syntax: true})
+	testSearchTopKPruned(t, f, SearchOptions{Syntax: true, FieldWeights: map[string]float32{"title": 2}})
+	err := f.db.View(func(tx *bolt.Tx) error {
+		s, terms, _ := f.prepareSearch(tx, literalTerms([]byte("berlin")), SearchOptions{})
+		if lists := postingLists(s, terms); len(lists) != 2 {
+			t.Errorf("postingLists(berlin) returned %d lists; expected one per field", len(lists))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testSearchTopKPruned(t *testing.T, f *File, opts SearchOptions) {
+	for _, query := range []string{"berlin mauer museum", "berlin", "text museum", `"berlin mauer" mau*`} {
+		all, err := f.SearchWithOptions([]byte(query), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range []int{1, 10, 100, 5000} {
+			opts.Limit = k
+			results, err := f.SearchWithOptions([]byte(query), opts)
+			opts.Limit = 0
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := all
+			if k < len(all) {
+				expected = all[:k]
+			}
+			if fmt.Sprint(results) != fmt.Sprint(expected) {
+				t.Errorf("SearchWithOptions(%s, %+v, Limit %d) = %v; expected %v", query, opts.FieldWeights, k, results, expected)
+			}
+		}
+	}
+}
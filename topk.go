@@ -0,0 +1,54 @@
+package minsearch
+
+import "container/heap"
+
+// SearchTopK works like Search with maxResults <= 0 but only returns
+// the k best results in the same order.
+// The result set is still collected, but only its k best results
+// are selected by a heap and sorted.
+// If k <= 0 all results are returned.
+// Union searches with a MonotoneScorer stop reading the
+// results of the query's segments as soon as no other result can reach the k best results,
+// so they only collect a part of the result set.
+func (f *File) SearchTopK(query []byte, setOp SetOperation, k int) ([]Result, error) {
+	return f.SearchWithOptions(query, SearchOptions{SetOperation: setOp, Limit: k})
+}
+
+// topResults returns the k best results of the result set in order;
+// they are selected by a heap of size k, so the result set isn't sorted.
+func topResults(qr map[ID]Score, k int) []Result {
+	if len(qr) < k {
+		k = len(qr)
+	}
+	h := make(resultHeap, 0, k)
+	for id, score := range qr {
+		r := Result{ID: id, Score: score}
+		if len(h) < k {
+			heap.Push(&h, r)
+		} else if isBetter(r, h[0]) {
+			h[0] = r
+			heap.Fix(&h, 0)
+		}
+	}
+	results := []Result(h)
+	sortResults(results)
+	return results
+}
+
+// resultHeap is a heap of results with the worst result on top.
+type resultHeap []Result
+
+func (h resultHeap) Len() int           { return len(h) }
+func (h resultHeap) Less(i, j int) bool { return isBetter(h[j], h[i]) }
+func (h resultHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *resultHeap) Push(x interface{}) {
+	*h = append(*h, x.(Result))
+}
+
+func (h *resultHeap) Pop() interface{} {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
@@ -0,0 +1,41 @@
+package minsearch
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearchTopK(t *testing.T) {
+	f, cleanup := openTestFile(t, Options{})
+	defer cleanup()
+
+	var pairs []Pair
+	for id := ID(1); id <= 50; id++ {
+		text := []byte("berlin")
+		for i := ID(0); i < id%7; i++ {
+			text = append(text, " mauer"...)
+		}
+		pairs = append(pairs, Pair{ID: id, Text: text})
+	}
+	if err := f.IndexBatch(pairs, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := f.Search([]byte("berlin mauer"), Union, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []int{0, 1, 10, 50, 100} {
+		results, err := f.SearchTopK([]byte("berlin mauer"), Union, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := all
+		if k > 0 && k < len(all) {
+			expected = all[:k]
+		}
+		if fmt.Sprint(results) != fmt.Sprint(expected) {
+			t.Errorf("SearchTopK(%d) = %v; expected %v", k, results, expected)
+		}
+	}
+}